@@ -0,0 +1,233 @@
+package crm
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/wojciechkapala/google-maps-scraper/csvwriter"
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+// defaultCountryCode is the calling code of the phone numbers written
+// without one.
+const defaultCountryCode = "48"
+
+// Profile is the CSV layout a CRM imports: its column names and value
+// formats, and the dialect of the file.
+type Profile struct {
+	Name    string
+	Columns []csvwriter.Column
+	// Comma is the field separator, ',' when zero.
+	Comma rune
+	BOM   bool
+}
+
+// Config returns the configuration of the CSV writer of the profile.
+func (p *Profile) Config() csvwriter.Config {
+	return csvwriter.Config{Columns: p.Columns, Comma: p.Comma, BOM: p.BOM}
+}
+
+var profiles = map[string]*Profile{
+	// Pipedrive maps "Entity - Field" headers to the fields of the
+	// organization and its contact person.
+	"pipedrive": {
+		Name: "pipedrive",
+		Columns: []csvwriter.Column{
+			{Header: "Organization - Name", Value: title},
+			{Header: "Organization - Address", Value: address},
+			{Header: "Organization - Website", Value: website},
+			{Header: "Organization - NIP", Value: nip},
+			{Header: "Person - First name", Value: ownerFirstName},
+			{Header: "Person - Last name", Value: ownerLastName},
+			{Header: "Person - Email", Value: firstEmail},
+			{Header: "Person - Phone", Value: phone},
+		},
+	},
+	// HubSpot imports a company and its contact from one file, the domain
+	// associates the contact with the company.
+	"hubspot": {
+		Name: "hubspot",
+		Columns: []csvwriter.Column{
+			{Header: "Company name", Value: title},
+			{Header: "Company Domain Name", Value: domain},
+			{Header: "Website URL", Value: website},
+			{Header: "Phone Number", Value: phone},
+			{Header: "Street Address", Value: street},
+			{Header: "City", Value: city},
+			{Header: "First Name", Value: ownerFirstName},
+			{Header: "Last Name", Value: ownerLastName},
+			{Header: "Email", Value: firstEmail},
+			{Header: "NIP", Value: nip},
+		},
+	},
+	// local is our own CRM, which reads the file as Excel with Polish
+	// settings does.
+	"local": {
+		Name: "local",
+		Columns: []csvwriter.Column{
+			{Header: "Nazwa", Value: title},
+			{Header: "Imię", Value: ownerFirstName},
+			{Header: "Nazwisko", Value: ownerLastName},
+			{Header: "Telefon", Value: phone},
+			{Header: "E-mail", Value: emails},
+			{Header: "WWW", Value: website},
+			{Header: "Ulica", Value: street},
+			{Header: "Miasto", Value: city},
+			{Header: "NIP", Value: nip},
+			{Header: "REGON", Value: func(e *gmaps.Entry) string { return e.REGON }},
+			{Header: "KRS", Value: func(e *gmaps.Entry) string { return e.KRS }},
+			{Header: "Fraza", Value: func(e *gmaps.Entry) string { return e.Query }},
+			{Header: "Google Maps", Value: func(e *gmaps.Entry) string { return e.Link }},
+		},
+		Comma: ';',
+		BOM:   true,
+	},
+}
+
+// Names returns the names of the profiles.
+func Names() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Lookup returns the profile with the name.
+func Lookup(name string) (*Profile, error) {
+	p, ok := profiles[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("unknown CRM profile %q, use one of: %s", name, strings.Join(Names(), ", "))
+	}
+
+	return p, nil
+}
+
+// Owner returns the first and the last name of the owner of the business
+// from the first registry record naming them. Low confidence matches are
+// skipped, so that a lead is not addressed by a wrong name.
+func Owner(e *gmaps.Entry) (first, last string) {
+	for _, reg := range []*gmaps.CompanyRegistration{e.CEIDG, e.GUS, e.KRSRegistry} {
+		if reg == nil || reg.LowConfidence || reg.Owner() == "" {
+			continue
+		}
+
+		return strings.TrimSpace(reg.OwnerFirstName), strings.TrimSpace(reg.OwnerLastName)
+	}
+
+	return "", ""
+}
+
+// E164 formats the phone number as E.164, for example "+48601234567". A
+// number without a calling code is taken as Polish. A phone which does not
+// look like a number is returned as it is.
+func E164(phone string) string {
+	phone = strings.TrimSpace(phone)
+
+	var digits strings.Builder
+
+	plus := false
+
+	for _, r := range phone {
+		switch {
+		case r == '+' && digits.Len() == 0:
+			plus = true
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		}
+	}
+
+	d := digits.String()
+
+	switch {
+	case plus:
+	case strings.HasPrefix(d, "00"):
+		d = d[2:]
+	case len(d) == 9:
+		d = defaultCountryCode + d
+	case len(d) == 10 && d[0] == '0':
+		// the trunk prefix of the old area codes, "0 32 123 45 67"
+		d = defaultCountryCode + d[1:]
+	case len(d) == 11 && strings.HasPrefix(d, defaultCountryCode):
+	default:
+		return phone
+	}
+
+	// E.164 numbers have at most 15 digits
+	if len(d) < 8 || len(d) > 15 {
+		return phone
+	}
+
+	return "+" + d
+}
+
+func title(e *gmaps.Entry) string {
+	return e.Title
+}
+
+func street(e *gmaps.Entry) string {
+	return strings.TrimSpace(e.Address.Street + " " + e.Address.Number)
+}
+
+func city(e *gmaps.Entry) string {
+	return e.City
+}
+
+func address(e *gmaps.Entry) string {
+	return strings.Trim(street(e)+", "+e.City, " ,")
+}
+
+func website(e *gmaps.Entry) string {
+	return e.WebSite
+}
+
+// domain is the host of the website without "www.".
+func domain(e *gmaps.Entry) string {
+	u, err := url.Parse(e.WebSite)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+func phone(e *gmaps.Entry) string {
+	if e.Phone == "" {
+		return ""
+	}
+
+	return E164(e.Phone)
+}
+
+func firstEmail(e *gmaps.Entry) string {
+	if len(e.Emails) == 0 {
+		return ""
+	}
+
+	return e.Emails[0]
+}
+
+func emails(e *gmaps.Entry) string {
+	return strings.Join(e.Emails, ", ")
+}
+
+func nip(e *gmaps.Entry) string {
+	return e.NIP
+}
+
+func ownerFirstName(e *gmaps.Entry) string {
+	first, _ := Owner(e)
+
+	return first
+}
+
+func ownerLastName(e *gmaps.Entry) string {
+	_, last := Owner(e)
+
+	return last
+}
@@ -0,0 +1,131 @@
+package crm_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/crm"
+	"github.com/wojciechkapala/google-maps-scraper/csvwriter"
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+func Test_E164(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+	}{
+		{"601 234 567", "+48601234567"},
+		{"+48 601-234-567", "+48601234567"},
+		{"0048 601 234 567", "+48601234567"},
+		{"48601234567", "+48601234567"},
+		{"(0 32) 281 12 34", "+48322811234"},
+		{"+357 25 123456", "+35725123456"},
+		{"112", "112"},
+		{"zadzwoń", "zadzwoń"},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, crm.E164(tt.phone), tt.phone)
+	}
+}
+
+func Test_Owner(t *testing.T) {
+	e := &gmaps.Entry{
+		CEIDG: &gmaps.CompanyRegistration{OwnerFirstName: "Jan", OwnerLastName: "Kowalski", LowConfidence: true},
+	}
+
+	// a low confidence match does not name the owner
+	first, last := crm.Owner(e)
+	require.Empty(t, first)
+	require.Empty(t, last)
+
+	e.GUS = &gmaps.CompanyRegistration{OwnerFirstName: "Anna Maria", OwnerLastName: "Nowak-Wiśniewska"}
+
+	first, last = crm.Owner(e)
+	require.Equal(t, "Anna Maria", first)
+	require.Equal(t, "Nowak-Wiśniewska", last)
+}
+
+func Test_Lookup(t *testing.T) {
+	_, err := crm.Lookup("salesforce")
+	require.ErrorContains(t, err, "hubspot, local, pipedrive")
+
+	p, err := crm.Lookup(" HubSpot ")
+	require.NoError(t, err)
+	require.Equal(t, "hubspot", p.Name)
+}
+
+func write(t *testing.T, profile string, e *gmaps.Entry) [][]string {
+	t.Helper()
+
+	p, err := crm.Lookup(profile)
+	require.NoError(t, err)
+
+	in := make(chan scrapemate.Result, 1)
+	in <- scrapemate.Result{Data: e}
+
+	close(in)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, csvwriter.NewCSVWriter(&buf, p.Config()).Run(context.Background(), in))
+
+	r := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\ufeff")))
+	r.Comma = p.Config().Comma
+
+	if r.Comma == 0 {
+		r.Comma = ','
+	}
+
+	records, err := r.ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	return records
+}
+
+var kowalski = &gmaps.Entry{
+	Query:       "pompy ciepła Bytom",
+	Title:       "Pompy Kowalski",
+	Address:     gmaps.Address{Street: "Żeromskiego", Number: "12"},
+	City:        "Bytom",
+	WebSite:     "https://www.pompy-kowalski.pl/kontakt",
+	Phone:       "601 234 567",
+	Emails:      []string{"biuro@pompy-kowalski.pl", "serwis@pompy-kowalski.pl"},
+	NIP:         "6262626262",
+	SocialLinks: map[string]string{},
+	CEIDG:       &gmaps.CompanyRegistration{OwnerFirstName: "Jan", OwnerLastName: "Kowalski"},
+}
+
+func Test_Profiles(t *testing.T) {
+	records := write(t, "hubspot", kowalski)
+	row := map[string]string{}
+
+	for i, h := range records[0] {
+		row[h] = records[1][i]
+	}
+
+	require.Equal(t, "Pompy Kowalski", row["Company name"])
+	require.Equal(t, "pompy-kowalski.pl", row["Company Domain Name"])
+	require.Equal(t, "+48601234567", row["Phone Number"])
+	require.Equal(t, "Żeromskiego 12", row["Street Address"])
+	require.Equal(t, "Jan", row["First Name"])
+	require.Equal(t, "Kowalski", row["Last Name"])
+	require.Equal(t, "biuro@pompy-kowalski.pl", row["Email"])
+
+	records = write(t, "pipedrive", kowalski)
+	require.Equal(t, "Organization - Name", records[0][0])
+	require.Equal(t, "Żeromskiego 12, Bytom", records[1][1])
+
+	// the local CRM reads semicolon separated files with all the emails
+	records = write(t, "local", kowalski)
+	require.Equal(t, "Nazwa", records[0][0])
+	require.Contains(t, records[1], "biuro@pompy-kowalski.pl, serwis@pompy-kowalski.pl")
+	require.Contains(t, records[1], "pompy ciepła Bytom")
+}
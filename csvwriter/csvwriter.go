@@ -0,0 +1,251 @@
+package csvwriter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+// Quote tells which fields are quoted.
+type Quote string
+
+const (
+	// QuoteMinimal quotes the fields with a separator, a quote, a line
+	// break or a leading space, like encoding/csv.
+	QuoteMinimal Quote = "minimal"
+	// QuoteAll quotes every field.
+	QuoteAll Quote = "all"
+)
+
+// queryField is the search query of the entry, which is not a default
+// column.
+const queryField = "query"
+
+// utf8BOM makes Excel read the file as UTF-8.
+const utf8BOM = "\ufeff"
+
+// Column is a field of the entry written under a header.
+type Column struct {
+	// Field is the name of the default CSV column of the value.
+	Field  string
+	Header string
+	// Value computes the value instead of Field when set, for example to
+	// format it for an import.
+	Value func(*gmaps.Entry) string
+}
+
+// Config configures the columns and the dialect of the CSV file.
+type Config struct {
+	// Columns are written in order, all the default columns when empty.
+	Columns []Column
+	// Comma is the field separator, ',' when zero.
+	Comma rune
+	Quote Quote
+	// BOM starts the file with the UTF-8 byte order mark.
+	BOM bool
+}
+
+// Fields returns the names of the fields a column can be made of: the
+// default CSV columns and the query.
+func Fields() []string {
+	return append([]string{queryField}, (&gmaps.Entry{}).CsvHeaders()...)
+}
+
+// ParseColumns parses a column specification: a comma or newline separated
+// list of fields, each optionally followed by a colon and its header, for
+// example "title:Nazwa,phone:Telefon,nip". A specification starting with @
+// is read from the file it names.
+func ParseColumns(spec string) ([]Column, error) {
+	if strings.HasPrefix(spec, "@") {
+		data, err := os.ReadFile(spec[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		spec = string(data)
+	}
+
+	known := make(map[string]bool)
+	for _, f := range Fields() {
+		known[f] = true
+	}
+
+	var columns []Column
+
+	for _, item := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == '\n' }) {
+		item = strings.TrimSpace(item)
+		if item == "" || strings.HasPrefix(item, "#") {
+			continue
+		}
+
+		field, header, _ := strings.Cut(item, ":")
+		field, header = strings.TrimSpace(field), strings.TrimSpace(header)
+
+		if !known[field] {
+			return nil, fmt.Errorf("unknown column %q, use one of: %s", field, strings.Join(Fields(), ", "))
+		}
+
+		if header == "" {
+			header = field
+		}
+
+		columns = append(columns, Column{Field: field, Header: header})
+	}
+
+	return columns, nil
+}
+
+// ParseSeparator parses a field separator: a single character, or "tab".
+func ParseSeparator(s string) (rune, error) {
+	switch s {
+	case "":
+		return ',', nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+
+	r, size := utf8.DecodeRuneInString(s)
+	if size != len(s) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		return 0, fmt.Errorf("invalid separator %q", s)
+	}
+
+	return r, nil
+}
+
+// ParseQuote parses a quoting mode, minimal when s is empty.
+func ParseQuote(s string) (Quote, error) {
+	switch Quote(s) {
+	case "", QuoteMinimal:
+		return QuoteMinimal, nil
+	case QuoteAll:
+		return QuoteAll, nil
+	default:
+		return "", fmt.Errorf("unknown quoting %q, use %q or %q", s, QuoteMinimal, QuoteAll)
+	}
+}
+
+// NewCSVWriter returns a writer of the entries as CSV with the configured
+// columns, separator and quoting. Every row is flushed as it is written.
+func NewCSVWriter(w io.Writer, cfg Config) scrapemate.ResultWriter {
+	if len(cfg.Columns) == 0 {
+		for _, f := range (&gmaps.Entry{}).CsvHeaders() {
+			cfg.Columns = append(cfg.Columns, Column{Field: f, Header: f})
+		}
+	}
+
+	if cfg.Comma == 0 {
+		cfg.Comma = ','
+	}
+
+	return &csvWriter{w: bufio.NewWriter(w), cfg: cfg}
+}
+
+type csvWriter struct {
+	w   *bufio.Writer
+	cfg Config
+}
+
+func (c *csvWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	if c.cfg.BOM {
+		if _, err := c.w.WriteString(utf8BOM); err != nil {
+			return err
+		}
+	}
+
+	headers := make([]string, len(c.cfg.Columns))
+	for i, col := range c.cfg.Columns {
+		headers[i] = col.Header
+	}
+
+	if err := c.writeRecord(headers); err != nil {
+		return err
+	}
+
+	for result := range in {
+		entries, err := asEntries(result.Data)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			if err := c.writeRecord(c.row(e)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return c.w.Flush()
+}
+
+func (c *csvWriter) row(e *gmaps.Entry) []string {
+	values := map[string]string{queryField: e.Query}
+
+	row := e.CsvRow()
+	for i, h := range e.CsvHeaders() {
+		values[h] = row[i]
+	}
+
+	record := make([]string, len(c.cfg.Columns))
+	for i, col := range c.cfg.Columns {
+		if col.Value != nil {
+			record[i] = col.Value(e)
+			continue
+		}
+
+		record[i] = values[col.Field]
+	}
+
+	return record
+}
+
+func (c *csvWriter) writeRecord(record []string) error {
+	for i, field := range record {
+		if i > 0 {
+			if _, err := c.w.WriteRune(c.cfg.Comma); err != nil {
+				return err
+			}
+		}
+
+		if c.cfg.Quote == QuoteAll || c.needsQuotes(field) {
+			field = `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+		}
+
+		if _, err := c.w.WriteString(field); err != nil {
+			return err
+		}
+	}
+
+	if err := c.w.WriteByte('\n'); err != nil {
+		return err
+	}
+
+	return c.w.Flush()
+}
+
+func (c *csvWriter) needsQuotes(field string) bool {
+	if field == "" {
+		return false
+	}
+
+	return field[0] == ' ' || field[0] == '\t' || strings.ContainsRune(field, c.cfg.Comma) || strings.ContainsAny(field, "\"\r\n")
+}
+
+func asEntries(data any) ([]*gmaps.Entry, error) {
+	switch v := data.(type) {
+	case *gmaps.Entry:
+		return []*gmaps.Entry{v}, nil
+	case []*gmaps.Entry:
+		return v, nil
+	default:
+		return nil, errors.New("invalid data type")
+	}
+}
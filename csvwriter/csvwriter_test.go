@@ -0,0 +1,104 @@
+package csvwriter_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/csvwriter"
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+func write(t *testing.T, cfg csvwriter.Config, entries ...*gmaps.Entry) string {
+	t.Helper()
+
+	in := make(chan scrapemate.Result, len(entries))
+	for _, e := range entries {
+		in <- scrapemate.Result{Data: e}
+	}
+
+	close(in)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, csvwriter.NewCSVWriter(&buf, cfg).Run(context.Background(), in))
+
+	return buf.String()
+}
+
+var kowalski = &gmaps.Entry{
+	Query:       "pompy ciepła Bytom",
+	Title:       `Pompy "Kowalski"; montaż`,
+	City:        "Bytom",
+	Phone:       "+48 601 234 567",
+	NIP:         "5260250274",
+	SocialLinks: map[string]string{},
+}
+
+func Test_CSVWriterDefault(t *testing.T) {
+	out := write(t, csvwriter.Config{}, kowalski)
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, kowalski.CsvHeaders(), records[0])
+	require.Equal(t, kowalski.CsvRow(), records[1])
+}
+
+func Test_CSVWriterColumns(t *testing.T) {
+	columns, err := csvwriter.ParseColumns("title:Nazwa, phone:Telefon,nip,query:Zapytanie")
+	require.NoError(t, err)
+
+	out := write(t, csvwriter.Config{Columns: columns, Comma: ';', BOM: true}, kowalski)
+
+	require.True(t, strings.HasPrefix(out, "\ufeff"))
+	require.Equal(t, "Nazwa;Telefon;nip;Zapytanie\n"+
+		`"Pompy ""Kowalski""; montaż";+48 601 234 567;5260250274;pompy ciepła Bytom`+"\n",
+		strings.TrimPrefix(out, "\ufeff"))
+}
+
+func Test_CSVWriterQuoteAll(t *testing.T) {
+	out := write(t, csvwriter.Config{
+		Columns: []csvwriter.Column{{Field: "city", Header: "Miasto"}, {Field: "emails", Header: "E-mail"}},
+		Quote:   csvwriter.QuoteAll,
+	}, kowalski)
+
+	require.Equal(t, "\"Miasto\",\"E-mail\"\n\"Bytom\",\"\"\n", out)
+}
+
+func Test_ParseColumns(t *testing.T) {
+	_, err := csvwriter.ParseColumns("title,fax")
+	require.ErrorContains(t, err, `unknown column "fax"`)
+
+	columns, err := csvwriter.ParseColumns("")
+	require.NoError(t, err)
+	require.Empty(t, columns)
+
+	spec := filepath.Join(t.TempDir(), "columns.txt")
+	require.NoError(t, os.WriteFile(spec, []byte("# kolumny dla działu sprzedaży\ntitle:Firma\nemails:E-mail\n"), 0o600))
+
+	columns, err = csvwriter.ParseColumns("@" + spec)
+	require.NoError(t, err)
+	require.Equal(t, []csvwriter.Column{{Field: "title", Header: "Firma"}, {Field: "emails", Header: "E-mail"}}, columns)
+}
+
+func Test_ParseSeparator(t *testing.T) {
+	for s, want := range map[string]rune{"": ',', ";": ';', "tab": '\t', "|": '|'} {
+		got, err := csvwriter.ParseSeparator(s)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	_, err := csvwriter.ParseSeparator(";;")
+	require.Error(t, err)
+
+	_, err = csvwriter.ParseSeparator(`"`)
+	require.Error(t, err)
+}
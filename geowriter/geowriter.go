@@ -0,0 +1,233 @@
+package geowriter
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+const (
+	queryProperty = "query"
+	// noQuery groups the entries scraped without a query.
+	noQuery = "Results"
+)
+
+// NewGeoJSONWriter returns a writer of a GeoJSON FeatureCollection with a
+// point for every entry with coordinates, grouped by query. The collection
+// is written once all the results have been received.
+func NewGeoJSONWriter(w io.Writer) scrapemate.ResultWriter {
+	return &geoWriter{w: w, write: WriteGeoJSON}
+}
+
+// NewKMLWriter returns a writer of a KML document with a folder of points
+// for every query. The document is written once all the results have been
+// received.
+func NewKMLWriter(w io.Writer) scrapemate.ResultWriter {
+	return &geoWriter{w: w, write: WriteKML}
+}
+
+type geoWriter struct {
+	w     io.Writer
+	write func(io.Writer, []*gmaps.Entry) error
+}
+
+func (g *geoWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	var entries []*gmaps.Entry
+
+	for result := range in {
+		entry, ok := result.Data.(*gmaps.Entry)
+		if !ok {
+			return errors.New("invalid data type")
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return g.write(g.w, entries)
+}
+
+type group struct {
+	query   string
+	entries []*gmaps.Entry
+}
+
+// groupByQuery returns the entries with coordinates grouped by query, in
+// the order the queries were first seen.
+func groupByQuery(entries []*gmaps.Entry) []*group {
+	var (
+		groups  []*group
+		byQuery = map[string]*group{}
+	)
+
+	for _, e := range entries {
+		if !e.HasCoordinates() {
+			continue
+		}
+
+		query := e.Query
+		if query == "" {
+			query = noQuery
+		}
+
+		g, ok := byQuery[query]
+		if !ok {
+			g = &group{query: query}
+			byQuery[query] = g
+			groups = append(groups, g)
+		}
+
+		g.entries = append(g.entries, e)
+	}
+
+	return groups
+}
+
+// property is a non empty CSV column of an entry.
+type property struct {
+	name, value string
+}
+
+func properties(e *gmaps.Entry) []property {
+	props := []property{{queryProperty, e.Query}}
+
+	row := e.CsvRow()
+	for i, h := range e.CsvHeaders() {
+		props = append(props, property{h, row[i]})
+	}
+
+	var nonEmpty []property
+
+	for _, p := range props {
+		if p.value != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+
+	return nonEmpty
+}
+
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   geoJSONPoint      `json:"geometry"`
+	Properties map[string]string `json:"properties"`
+}
+
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// WriteGeoJSON writes the entries with coordinates as a GeoJSON
+// FeatureCollection of points, ordered by query. The properties of a point
+// are the non empty CSV columns of its entry and its query.
+func WriteGeoJSON(w io.Writer, entries []*gmaps.Entry) error {
+	features := []geoJSONFeature{}
+
+	for _, g := range groupByQuery(entries) {
+		for _, e := range g.entries {
+			props := map[string]string{}
+			for _, p := range properties(e) {
+				props[p.name] = p.value
+			}
+
+			props[queryProperty] = g.query
+
+			features = append(features, geoJSONFeature{
+				Type: "Feature",
+				// GeoJSON puts the longitude first
+				Geometry:   geoJSONPoint{Type: "Point", Coordinates: [2]float64{e.Longitude, e.Latitude}},
+				Properties: props,
+			})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(struct {
+		Type     string           `json:"type"`
+		Features []geoJSONFeature `json:"features"`
+	}{"FeatureCollection", features})
+}
+
+type kmlDocument struct {
+	XMLName xml.Name    `xml:"kml"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	Name    string      `xml:"Document>name"`
+	Folders []kmlFolder `xml:"Document>Folder"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name        string    `xml:"name"`
+	Address     string    `xml:"address,omitempty"`
+	Phone       string    `xml:"phoneNumber,omitempty"`
+	Description string    `xml:"description,omitempty"`
+	Data        []kmlData `xml:"ExtendedData>Data"`
+	Coordinates string    `xml:"Point>coordinates"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+// WriteKML writes the entries with coordinates as a KML document with a
+// folder of placemarks for every query. The extended data of a placemark
+// are the non empty CSV columns of its entry.
+func WriteKML(w io.Writer, entries []*gmaps.Entry) error {
+	doc := kmlDocument{
+		XMLNS: "http://www.opengis.net/kml/2.2",
+		Name:  "google-maps-scraper",
+	}
+
+	for _, g := range groupByQuery(entries) {
+		folder := kmlFolder{Name: g.query}
+
+		for _, e := range g.entries {
+			placemark := kmlPlacemark{
+				Name:        e.Title,
+				Address:     strings.Trim(fmt.Sprintf("%s %s, %s", e.Address.Street, e.Address.Number, e.City), " ,"),
+				Phone:       e.Phone,
+				Description: e.WebSite,
+				Coordinates: strconv.FormatFloat(e.Longitude, 'f', 7, 64) + "," + strconv.FormatFloat(e.Latitude, 'f', 7, 64),
+			}
+
+			for _, p := range properties(e) {
+				placemark.Data = append(placemark.Data, kmlData{Name: p.name, Value: p.value})
+			}
+
+			folder.Placemarks = append(folder.Placemarks, placemark)
+		}
+
+		doc.Folders = append(doc.Folders, folder)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
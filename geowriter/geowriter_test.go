@@ -0,0 +1,123 @@
+package geowriter_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/geowriter"
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+func entries(t *testing.T) []*gmaps.Entry {
+	t.Helper()
+
+	raw, err := os.ReadFile("../testdata/raw.json")
+	require.NoError(t, err)
+
+	kipriakon, err := gmaps.EntryFromJSON(raw)
+	require.NoError(t, err)
+
+	kipriakon.Query = "restaurants in Limassol"
+
+	return []*gmaps.Entry{
+		&kipriakon,
+		{Query: "pompy ciepła Bytom", Title: "Pompy & Kowalski", City: "Bytom", Phone: "601 234 567", Latitude: 50.348, Longitude: 18.915, SocialLinks: map[string]string{}},
+		// no coordinates, left out
+		{Query: "pompy ciepła Bytom", Title: "Bez lokalizacji", SocialLinks: map[string]string{}},
+		{Query: "restaurants in Limassol", Title: "Second", Latitude: 34.7, Longitude: 33.1, SocialLinks: map[string]string{}},
+	}
+}
+
+func run(t *testing.T, w func(*bytes.Buffer) scrapemate.ResultWriter, items []*gmaps.Entry) []byte {
+	t.Helper()
+
+	in := make(chan scrapemate.Result, len(items))
+	for _, e := range items {
+		in <- scrapemate.Result{Data: e}
+	}
+
+	close(in)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, w(&buf).Run(context.Background(), in))
+
+	return buf.Bytes()
+}
+
+func Test_GeoJSONWriter(t *testing.T) {
+	out := run(t, func(b *bytes.Buffer) scrapemate.ResultWriter { return geowriter.NewGeoJSONWriter(b) }, entries(t))
+
+	var fc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type        string     `json:"type"`
+				Coordinates [2]float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]string `json:"properties"`
+		} `json:"features"`
+	}
+
+	require.NoError(t, json.Unmarshal(out, &fc))
+	require.Equal(t, "FeatureCollection", fc.Type)
+	require.Len(t, fc.Features, 3)
+
+	// grouped by query, longitude first
+	first := fc.Features[0]
+	require.Equal(t, "Point", first.Geometry.Type)
+	require.InDelta(t, 33.0424567, first.Geometry.Coordinates[0], 1e-6)
+	require.InDelta(t, 34.6705954, first.Geometry.Coordinates[1], 1e-6)
+	require.Equal(t, "Kipriakon", first.Properties["title"])
+	require.Equal(t, "restaurants in Limassol", first.Properties["query"])
+
+	require.Equal(t, "Second", fc.Features[1].Properties["title"])
+	require.Equal(t, "Pompy & Kowalski", fc.Features[2].Properties["title"])
+	require.Equal(t, "601 234 567", fc.Features[2].Properties["phone"])
+	require.NotContains(t, fc.Features[2].Properties, "emails")
+}
+
+func Test_KMLWriter(t *testing.T) {
+	out := run(t, func(b *bytes.Buffer) scrapemate.ResultWriter { return geowriter.NewKMLWriter(b) }, entries(t))
+
+	var doc struct {
+		Folders []struct {
+			Name       string `xml:"name"`
+			Placemarks []struct {
+				Name        string `xml:"name"`
+				Coordinates string `xml:"Point>coordinates"`
+				Data        []struct {
+					Name  string `xml:"name,attr"`
+					Value string `xml:"value"`
+				} `xml:"ExtendedData>Data"`
+			} `xml:"Placemark"`
+		} `xml:"Document>Folder"`
+	}
+
+	require.NoError(t, xml.Unmarshal(out, &doc))
+	require.Len(t, doc.Folders, 2)
+	require.Equal(t, "restaurants in Limassol", doc.Folders[0].Name)
+	require.Len(t, doc.Folders[0].Placemarks, 2)
+
+	pompy := doc.Folders[1].Placemarks[0]
+	require.Equal(t, "Pompy & Kowalski", pompy.Name)
+	require.Equal(t, "18.9150000,50.3480000", pompy.Coordinates)
+	require.Contains(t, pompy.Data, struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value"`
+	}{"city", "Bytom"})
+}
+
+func Test_WriteGeoJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, geowriter.WriteGeoJSON(&buf, nil))
+	require.JSONEq(t, `{"type": "FeatureCollection", "features": []}`, buf.String())
+}
@@ -0,0 +1,371 @@
+package gmaps
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/gosom/scrapemate"
+)
+
+const (
+	birDefaultURL     = "https://wyszukiwarkaregon.stat.gov.pl/wsBIR/UslugaBIRzewnPubl.svc"
+	birDefaultTimeout = 10 * time.Second
+	// birSessionTTL is shorter than the 60 minutes after which GUS drops
+	// an idle session.
+	birSessionTTL = 50 * time.Minute
+
+	birActionPrefix = "http://CIS/BIR/PUBL/2014/07/IUslugaBIRzewnPubl/"
+
+	envBIRAPIKey  = "GUS_BIR_API_KEY"
+	envBIRURL     = "GUS_BIR_URL"
+	envBIRTimeout = "GUS_BIR_TIMEOUT"
+)
+
+// BIRClient looks up entities in the GUS REGON database through the BIR
+// (Baza Internetowa REGON) service.
+type BIRClient interface {
+	// SearchNIP returns the entities registered under nip.
+	SearchNIP(ctx context.Context, nip string) ([]BIRRecord, error)
+	// SearchREGON returns the entities registered under regon.
+	SearchREGON(ctx context.Context, regon string) ([]BIRRecord, error)
+}
+
+// BIRRecord is a single result of the BIR DaneSzukajPodmioty operation.
+type BIRRecord struct {
+	Regon                       string `xml:"Regon"`
+	Nip                         string `xml:"Nip"`
+	StatusNip                   string `xml:"StatusNip"`
+	Nazwa                       string `xml:"Nazwa"`
+	Wojewodztwo                 string `xml:"Wojewodztwo"`
+	Powiat                      string `xml:"Powiat"`
+	Gmina                       string `xml:"Gmina"`
+	Miejscowosc                 string `xml:"Miejscowosc"`
+	KodPocztowy                 string `xml:"KodPocztowy"`
+	Ulica                       string `xml:"Ulica"`
+	NrNieruchomosci             string `xml:"NrNieruchomosci"`
+	NrLokalu                    string `xml:"NrLokalu"`
+	Typ                         string `xml:"Typ"`
+	DataZakonczeniaDzialalnosci string `xml:"DataZakonczeniaDzialalnosci"`
+	ErrorCode                   string `xml:"ErrorCode"`
+}
+
+// Registration converts the record to a CompanyRegistration.
+func (r *BIRRecord) Registration() *CompanyRegistration {
+	building := r.NrNieruchomosci
+	if r.NrLokalu != "" {
+		building += "/" + r.NrLokalu
+	}
+
+	status := "AKTYWNY"
+	if r.DataZakonczeniaDzialalnosci != "" {
+		status = "ZAKOŃCZONY " + r.DataZakonczeniaDzialalnosci
+	}
+
+	return &CompanyRegistration{
+		Source: SourceGUS,
+		ID:     r.Regon,
+		Name:   r.Nazwa,
+		NIP:    r.Nip,
+		REGON:  r.Regon,
+		Address: RegistrationAddress{
+			Street:      r.Ulica,
+			Building:    building,
+			PostalCode:  r.KodPocztowy,
+			City:        r.Miejscowosc,
+			Commune:     r.Gmina,
+			County:      r.Powiat,
+			Voivodeship: r.Wojewodztwo,
+		},
+		Status: status,
+	}
+}
+
+// BIRConfig configures the BIR client.
+type BIRConfig struct {
+	URL     string
+	APIKey  string
+	Timeout time.Duration
+	// Limiter limits the rate of the requests and retries them, nil
+	// sends them as they come.
+	Limiter *RegistryLimiter
+}
+
+// LoadBIRConfig reads the GUS_BIR_API_KEY, GUS_BIR_URL and GUS_BIR_TIMEOUT
+// settings from a file in the .env format, or from the environment when
+// path is empty.
+func LoadBIRConfig(path string) (BIRConfig, error) {
+	values, err := readSettings(path, envBIRAPIKey, envBIRURL, envBIRTimeout)
+	if err != nil {
+		return BIRConfig{}, err
+	}
+
+	cfg := BIRConfig{
+		URL:    values[envBIRURL],
+		APIKey: strings.TrimSpace(values[envBIRAPIKey]),
+	}
+
+	cfg.Timeout, err = durationSetting(values, envBIRTimeout, birDefaultTimeout)
+	if err != nil {
+		return BIRConfig{}, err
+	}
+
+	return cfg, nil
+}
+
+// String implements fmt.Stringer and never prints the API key.
+func (c BIRConfig) String() string {
+	key := ""
+	if c.APIKey != "" {
+		key = redacted
+	}
+
+	return fmt.Sprintf("BIRConfig{URL: %s, APIKey: %s, Timeout: %s}", c.URL, key, c.Timeout)
+}
+
+var _ BIRClient = (*BIRSOAPClient)(nil)
+
+// BIRSOAPClient talks SOAP 1.2 to the BIR service. It logs in lazily and
+// keeps the session id until it expires.
+type BIRSOAPClient struct {
+	cfg    BIRConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	sid       string
+	sidExpiry time.Time
+}
+
+func NewBIRSOAPClient(cfg BIRConfig) (*BIRSOAPClient, error) {
+	if cfg.APIKey == "" {
+		return nil, ErrMissingAPIKey
+	}
+
+	if cfg.URL == "" {
+		cfg.URL = birDefaultURL
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = birDefaultTimeout
+	}
+
+	return &BIRSOAPClient{
+		cfg:    cfg,
+		client: registryHTTPClient(cfg.Timeout, cfg.Limiter),
+	}, nil
+}
+
+func (c *BIRSOAPClient) SearchNIP(ctx context.Context, nip string) ([]BIRRecord, error) {
+	return c.search(ctx, "Nip", cleanNIP(nip))
+}
+
+func (c *BIRSOAPClient) SearchREGON(ctx context.Context, regon string) ([]BIRRecord, error) {
+	return c.search(ctx, "Regon", cleanNIP(regon))
+}
+
+func (c *BIRSOAPClient) search(ctx context.Context, param, value string) ([]BIRRecord, error) {
+	if !isDigits(value) {
+		return nil, nil
+	}
+
+	var body bytes.Buffer
+
+	if err := birSearchTmpl.Execute(&body, struct{ Param, Value string }{param, value}); err != nil {
+		return nil, err
+	}
+
+	var result string
+
+	// an empty result means that GUS dropped the session before it
+	// expired, the search is sent once more with a new one
+	for attempt := 0; strings.TrimSpace(result) == ""; attempt++ {
+		if attempt == 2 {
+			return nil, errors.New("bir: empty response with a new session")
+		}
+
+		sid, err := c.session(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if result, err = c.call(ctx, "DaneSzukajPodmioty", sid, body.String()); err != nil {
+			return nil, err
+		}
+
+		if strings.TrimSpace(result) == "" {
+			c.dropSession(sid)
+		}
+	}
+
+	var root struct {
+		Dane []BIRRecord `xml:"dane"`
+	}
+
+	if err := xml.Unmarshal([]byte(result), &root); err != nil {
+		return nil, fmt.Errorf("bir: %w", err)
+	}
+
+	var records []BIRRecord
+
+	for i := range root.Dane {
+		// ErrorCode 4 means that nothing was found
+		if root.Dane[i].ErrorCode == "" {
+			records = append(records, root.Dane[i])
+		}
+	}
+
+	return records, nil
+}
+
+func (c *BIRSOAPClient) session(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sid != "" && time.Now().Before(c.sidExpiry) {
+		return c.sid, nil
+	}
+
+	var body bytes.Buffer
+
+	if err := xml.EscapeText(&body, []byte(c.cfg.APIKey)); err != nil {
+		return "", err
+	}
+
+	sid, err := c.call(ctx, "Zaloguj", "", "<ns:Zaloguj><ns:pKluczUzytkownika>"+body.String()+"</ns:pKluczUzytkownika></ns:Zaloguj>")
+	if err != nil {
+		return "", err
+	}
+
+	if sid == "" {
+		return "", errors.New("bir: login failed, check the API key")
+	}
+
+	c.sid = sid
+	c.sidExpiry = time.Now().Add(birSessionTTL)
+
+	return sid, nil
+}
+
+// dropSession forgets the session sid, unless another search has already
+// replaced it.
+func (c *BIRSOAPClient) dropSession(sid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sid == sid {
+		c.sid = ""
+	}
+}
+
+// call sends a SOAP request and returns the text of the <action>Result
+// element of the response.
+func (c *BIRSOAPClient) call(ctx context.Context, action, sid, body string) (string, error) {
+	var envelope bytes.Buffer
+
+	err := birEnvelopeTmpl.Execute(&envelope, struct{ URL, Action, Body string }{
+		URL:    c.cfg.URL,
+		Action: birActionPrefix + action,
+		Body:   body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.URL, &envelope)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/soap+xml; charset=utf-8")
+
+	if sid != "" {
+		req.Header.Set("sid", sid)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", redactError(err, c.cfg.APIKey)
+	}
+
+	defer resp.Body.Close()
+
+	scrapemate.GetLoggerFromContext(ctx).Debug("BIR response", "action", action, "statusCode", resp.StatusCode)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("bir: unexpected status code %d", resp.StatusCode)
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return soapResult(raw, action+"Result")
+}
+
+// soapResult returns the text of the first element named name. The BIR
+// service answers with MTOM, so the envelope is looked up inside the body
+// instead of decoding the whole response.
+func soapResult(raw []byte, name string) (string, error) {
+	start := bytes.Index(raw, []byte("<s:Envelope"))
+	if start < 0 {
+		start = 0
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(raw[start:]))
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("bir: %s not found in response", name)
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("bir: %w", err)
+		}
+
+		if el, ok := tok.(xml.StartElement); ok && el.Name.Local == name {
+			var text string
+			if err := dec.DecodeElement(&text, &el); err != nil {
+				return "", fmt.Errorf("bir: %w", err)
+			}
+
+			return text, nil
+		}
+	}
+}
+
+// birTmplFuncs escapes the values interpolated in the templates, which
+// text/template leaves as they are.
+var birTmplFuncs = template.FuncMap{
+	"xml": func(s string) (string, error) {
+		var b strings.Builder
+		if err := xml.EscapeText(&b, []byte(s)); err != nil {
+			return "", err
+		}
+
+		return b.String(), nil
+	},
+}
+
+var birEnvelopeTmpl = template.Must(template.New("envelope").Funcs(birTmplFuncs).Parse(
+	`<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope" ` +
+		`xmlns:ns="http://CIS/BIR/PUBL/2014/07" xmlns:dat="http://CIS/BIR/PUBL/2014/07/DataContract">` +
+		`<soap:Header xmlns:wsa="http://www.w3.org/2005/08/addressing">` +
+		`<wsa:To>{{xml .URL}}</wsa:To><wsa:Action>{{xml .Action}}</wsa:Action>` +
+		`</soap:Header><soap:Body>{{.Body}}</soap:Body></soap:Envelope>`,
+))
+
+var birSearchTmpl = template.Must(template.New("search").Funcs(birTmplFuncs).Parse(
+	`<ns:DaneSzukajPodmioty><ns:pParametryWyszukiwania>` +
+		`<dat:{{.Param}}>{{xml .Value}}</dat:{{.Param}}>` +
+		`</ns:pParametryWyszukiwania></ns:DaneSzukajPodmioty>`,
+))
@@ -0,0 +1,293 @@
+package gmaps
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const cacheFileExt = ".json"
+
+// DefaultCacheTTLs are the cache TTLs used for the sources not listed in
+// the -cache-ttl flag.
+var DefaultCacheTTLs = map[string]time.Duration{
+	SourceWebsite: 7 * 24 * time.Hour,
+	SourceCEIDG:   30 * 24 * time.Hour,
+	SourceVIES:    24 * time.Hour,
+}
+
+// DiskCache keeps website pages and registry answers between runs. Entries
+// are stored as one JSON file per key in a directory per source, and expire
+// after the TTL of their source. A nil *DiskCache caches nothing.
+type DiskCache struct {
+	dir  string
+	ttls map[string]time.Duration
+}
+
+type cacheEntry struct {
+	Key      string          `json:"key"`
+	StoredAt time.Time       `json:"stored_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// CacheStats describes the entries of a single source.
+type CacheStats struct {
+	Source  string
+	Entries int
+	Expired int
+	Bytes   int64
+}
+
+// NewDiskCache opens the cache in dir, creating the directory if needed.
+// Sources missing from ttls use DefaultCacheTTLs. A TTL of zero disables
+// caching of the source.
+func NewDiskCache(dir string, ttls map[string]time.Duration) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+
+	merged := make(map[string]time.Duration, len(DefaultCacheTTLs)+len(ttls))
+	for source, ttl := range DefaultCacheTTLs {
+		merged[source] = ttl
+	}
+
+	for source, ttl := range ttls {
+		merged[source] = ttl
+	}
+
+	return &DiskCache{dir: dir, ttls: merged}, nil
+}
+
+// ParseCacheTTLs parses TTLs in the source=duration,source=duration form,
+// for example "website=24h,ceidg=720h".
+func ParseCacheTTLs(s string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration)
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		source, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("cache: invalid TTL %q, expected source=duration", part)
+		}
+
+		ttl, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("cache: invalid TTL for %s: %w", source, err)
+		}
+
+		ttls[strings.TrimSpace(source)] = ttl
+	}
+
+	return ttls, nil
+}
+
+// Get decodes the cached value of key into v. It reports false when there
+// is no entry or the entry has expired.
+func (c *DiskCache) Get(source, key string, v any) (bool, error) {
+	ttl := c.ttl(source)
+	if ttl <= 0 {
+		return false, nil
+	}
+
+	raw, err := os.ReadFile(c.path(source, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("cache: %w", err)
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return false, fmt.Errorf("cache: %w", err)
+	}
+
+	if entry.Key != key || time.Since(entry.StoredAt) > ttl {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(entry.Value, v); err != nil {
+		return false, fmt.Errorf("cache: %w", err)
+	}
+
+	return true, nil
+}
+
+// Set stores v under key. The file is written next to its final path and
+// renamed, so concurrent jobs never read a partial entry.
+func (c *DiskCache) Set(source, key string, v any) error {
+	if c.ttl(source) <= 0 {
+		return nil
+	}
+
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+
+	raw, err := json.Marshal(cacheEntry{Key: key, StoredAt: time.Now().UTC(), Value: value})
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+
+	path := c.path(source, key)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "tmp-*")
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return fmt.Errorf("cache: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+
+		return fmt.Errorf("cache: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+
+		return fmt.Errorf("cache: %w", err)
+	}
+
+	return nil
+}
+
+// Stats returns the number and size of the entries of every source, sorted
+// by source.
+func (c *DiskCache) Stats() ([]CacheStats, error) {
+	var stats []CacheStats
+
+	err := c.walk(func(source, path string, info fs.FileInfo, expired bool) error {
+		if len(stats) == 0 || stats[len(stats)-1].Source != source {
+			stats = append(stats, CacheStats{Source: source})
+		}
+
+		s := &stats[len(stats)-1]
+		s.Entries++
+		s.Bytes += info.Size()
+
+		if expired {
+			s.Expired++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Source < stats[j].Source
+	})
+
+	return stats, nil
+}
+
+// Purge removes the entries of the given sources, or of all the sources
+// when none is given. With expiredOnly only the expired entries are removed.
+// It returns the number of removed entries.
+func (c *DiskCache) Purge(expiredOnly bool, sources ...string) (int, error) {
+	wanted := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		wanted[source] = true
+	}
+
+	removed := 0
+
+	err := c.walk(func(source, path string, _ fs.FileInfo, expired bool) error {
+		if len(wanted) > 0 && !wanted[source] {
+			return nil
+		}
+
+		if expiredOnly && !expired {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+
+		removed++
+
+		return nil
+	})
+
+	return removed, err
+}
+
+// walk calls fn for every entry file. An entry is expired when it is older
+// than the TTL of its source, judged by the modification time of the file.
+func (c *DiskCache) walk(fn func(source, path string, info fs.FileInfo, expired bool) error) error {
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || filepath.Ext(path) != cacheFileExt {
+			return nil
+		}
+
+		rel, err := filepath.Rel(c.dir, path)
+		if err != nil {
+			return err
+		}
+
+		source, _, ok := strings.Cut(filepath.ToSlash(rel), "/")
+		if !ok {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		expired := time.Since(info.ModTime()) > c.ttl(source)
+
+		return fn(source, path, info, expired)
+	})
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+
+	return nil
+}
+
+func (c *DiskCache) ttl(source string) time.Duration {
+	if c == nil {
+		return 0
+	}
+
+	return c.ttls[source]
+}
+
+func (c *DiskCache) path(source, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+
+	return filepath.Join(c.dir, source, name[:2], name+cacheFileExt)
+}
@@ -0,0 +1,115 @@
+package gmaps_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+func Test_DiskCache(t *testing.T) {
+	dir := t.TempDir()
+
+	cache, err := gmaps.NewDiskCache(dir, map[string]time.Duration{gmaps.SourceCEIDG: time.Nanosecond})
+	require.NoError(t, err)
+
+	var body []byte
+
+	ok, err := cache.Get(gmaps.SourceWebsite, "https://example.com", &body)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, cache.Set(gmaps.SourceWebsite, "https://example.com", []byte("<html></html>")))
+	require.NoError(t, cache.Set(gmaps.SourceCEIDG, "5260250274", []gmaps.CEIDGFirm{{Nazwa: "Firma"}}))
+
+	ok, err = cache.Get(gmaps.SourceWebsite, "https://example.com", &body)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "<html></html>", string(body))
+
+	// the entries are kept across instances
+	reopened, err := gmaps.NewDiskCache(dir, nil)
+	require.NoError(t, err)
+
+	var firms []gmaps.CEIDGFirm
+
+	ok, err = reopened.Get(gmaps.SourceCEIDG, "5260250274", &firms)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "Firma", firms[0].Nazwa)
+
+	// the CEIDG entry has expired with the short TTL
+	ok, err = cache.Get(gmaps.SourceCEIDG, "5260250274", &firms)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	stats, err := cache.Stats()
+	require.NoError(t, err)
+	require.Equal(t, []gmaps.CacheStats{
+		{Source: gmaps.SourceCEIDG, Entries: 1, Expired: 1, Bytes: stats[0].Bytes},
+		{Source: gmaps.SourceWebsite, Entries: 1, Expired: 0, Bytes: stats[1].Bytes},
+	}, stats)
+
+	removed, err := cache.Purge(true)
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+
+	removed, err = cache.Purge(false, gmaps.SourceWebsite)
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+
+	stats, err = cache.Stats()
+	require.NoError(t, err)
+	require.Empty(t, stats)
+}
+
+func Test_DiskCacheNil(t *testing.T) {
+	var cache *gmaps.DiskCache
+
+	require.NoError(t, cache.Set(gmaps.SourceWebsite, "https://example.com", "x"))
+
+	var v string
+
+	ok, err := cache.Get(gmaps.SourceWebsite, "https://example.com", &v)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func Test_ParseCacheTTLs(t *testing.T) {
+	ttls, err := gmaps.ParseCacheTTLs("website=24h, ceidg=0")
+	require.NoError(t, err)
+	require.Equal(t, map[string]time.Duration{gmaps.SourceWebsite: 24 * time.Hour, gmaps.SourceCEIDG: 0}, ttls)
+
+	_, err = gmaps.ParseCacheTTLs("website")
+	require.Error(t, err)
+
+	_, err = gmaps.ParseCacheTTLs("website=soon")
+	require.Error(t, err)
+}
+
+func Test_CachedRegistry(t *testing.T) {
+	cache, err := gmaps.NewDiskCache(t.TempDir(), nil)
+	require.NoError(t, err)
+
+	registry := gmaps.NewInMemoryRegistry(map[string][]gmaps.CEIDGFirm{
+		"5260250274": {{Nazwa: "Firma"}},
+	})
+
+	client := gmaps.NewCachedRegistry(registry, cache)
+
+	for i := 0; i < 2; i++ {
+		firms, err := client.LookupNIP(context.Background(), "5260250274")
+		require.NoError(t, err)
+		require.Len(t, firms, 1)
+
+		// unknown NIPs are cached too
+		firms, err = client.LookupNIP(context.Background(), "1234563218")
+		require.NoError(t, err)
+		require.Empty(t, firms)
+	}
+
+	require.Len(t, registry.Lookups(), 2)
+}
@@ -2,41 +2,200 @@ package gmaps
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
+	"errors"
 	"net/http"
-	"regexp"
 	"strings"
-	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/google/uuid"
 	"github.com/gosom/scrapemate"
-	"github.com/joho/godotenv"
 	"github.com/mcnijman/go-emailaddress"
+	"github.com/playwright-community/playwright-go"
 )
 
+type EmailExtractJobOptions func(*EmailExtractJob)
+
 type EmailExtractJob struct {
 	scrapemate.Job
 	Entry *Entry
+
+	UsageInResults bool
+	Relevance      RelevanceConfig
+	// Render reads the website from the DOM rendered by the browser. It is
+	// set on the job the website is handed over to when its HTML has
+	// nothing to extract.
+	Render bool
+
+	enrichment *Enrichment
+	health     *WebsiteHealth
+	// needsRender hands the website over to a render job.
+	needsRender bool
 }
 
-type CEIDGExtractJob struct {
-	scrapemate.Job
-	Entry *Entry
+// cachedPage is a website page kept in the disk cache.
+type cachedPage struct {
+	Body   []byte         `json:"body"`
+	Health *WebsiteHealth `json:"health"`
 }
 
-func NewEmailJob(parentID string, entry *Entry) *EmailExtractJob {
-	return &EmailExtractJob{
+func NewEmailJob(parentID string, entry *Entry, opts ...EmailExtractJobOptions) *EmailExtractJob {
+	job := EmailExtractJob{
 		Job: scrapemate.Job{
+			ID:         uuid.New().String(),
 			ParentID:   parentID,
 			Method:     "GET",
 			URL:        entry.WebSite,
 			MaxRetries: 0,
 			Priority:   scrapemate.PriorityHigh,
 		},
-		Entry: entry,
+		Entry:          entry,
+		UsageInResults: true,
 	}
+
+	for _, opt := range opts {
+		opt(&job)
+	}
+
+	return &job
+}
+
+// WithEmailJobEnrichment sets the services used to enrich the entry once
+// its website has been processed.
+func WithEmailJobEnrichment(e *Enrichment) EmailExtractJobOptions {
+	return func(j *EmailExtractJob) {
+		j.enrichment = e
+	}
+}
+
+// WithEmailJobRelevance sets the terms the website is scored against and
+// the minimal score of an entry written to the results.
+func WithEmailJobRelevance(cfg RelevanceConfig) EmailExtractJobOptions {
+	return func(j *EmailExtractJob) {
+		j.Relevance = cfg
+	}
+}
+
+// BrowserActions serves the page from the disk cache when it has been
+// fetched before. Otherwise it visits the page within robots.txt and the
+// per host limits, profiles the website from the visit, and caches both.
+func (j *EmailExtractJob) BrowserActions(ctx context.Context, page playwright.Page) scrapemate.Response {
+	cache := j.enrichment.cache()
+	log := scrapemate.GetLoggerFromContext(ctx)
+
+	var cached cachedPage
+
+	ok, err := cache.Get(SourceWebsite, j.URL, &cached)
+	if err != nil {
+		log.Error("cache read failed", "source", SourceWebsite, "error", err)
+	}
+
+	if ok {
+		j.health = cached.Health
+
+		return scrapemate.Response{URL: j.URL, StatusCode: http.StatusOK, Body: cached.Body}
+	}
+
+	politeness := j.enrichment.politeness()
+
+	if !politeness.Allowed(ctx, j.URL) {
+		log.Info("website disallowed by robots.txt", "url", j.URL)
+
+		return scrapemate.Response{URL: j.URL, Error: ErrDisallowedByRobots}
+	}
+
+	release, err := politeness.Acquire(ctx, j.URL)
+	if err != nil {
+		return scrapemate.Response{URL: j.URL, Error: err}
+	}
+
+	defer release()
+
+	// the pages are shared with the other jobs, so the header is removed
+	// once the page has been visited
+	if ua := politeness.UserAgent(); ua != "" {
+		if err := page.SetExtraHTTPHeaders(map[string]string{"User-Agent": ua}); err == nil {
+			defer func() {
+				_ = page.SetExtraHTTPHeaders(map[string]string{})
+			}()
+		}
+	}
+
+	resp := j.visit(page)
+
+	// the browser follows the redirects on its own, the page is not used
+	// when robots.txt disallows one of them
+	if resp.Error == nil {
+		hops := append(append([]string(nil), j.health.Redirects...), j.health.FinalURL)
+
+		for _, u := range hops[1:] {
+			if !politeness.Allowed(ctx, u) {
+				log.Info("website redirected to a page disallowed by robots.txt", "url", j.URL, "redirect", u)
+
+				return scrapemate.Response{URL: j.URL, Error: ErrDisallowedByRobots}
+			}
+		}
+	}
+
+	if renderer := j.enrichment.renderer(); renderer != nil && resp.Error == nil {
+		switch {
+		case j.Render:
+			body, err := renderer.Render(ctx, page)
+			if err != nil {
+				log.Error("could not render website", "url", j.URL, "error", err)
+			} else {
+				resp.Body = body
+				j.health.Rendered = true
+			}
+		case NeedsRendering(resp.Body):
+			// rendering keeps the page busy, the page is rendered by a low
+			// priority job of its own
+			j.needsRender = true
+
+			return resp
+		}
+	}
+
+	if resp.Error == nil && resp.StatusCode == http.StatusOK {
+		if err := cache.Set(SourceWebsite, j.URL, cachedPage{Body: resp.Body, Health: j.health}); err != nil {
+			log.Error("cache write failed", "source", SourceWebsite, "error", err)
+		}
+	}
+
+	return resp
+}
+
+// visit navigates to the website like scrapemate.Job.BrowserActions and
+// profiles it from the navigation response, so that the website is
+// requested once.
+func (j *EmailExtractJob) visit(page playwright.Page) scrapemate.Response {
+	pageResponse, err := page.Goto(j.GetFullURL(), playwright.PageGotoOptions{
+		WaitUntil: playwright.WaitUntilStateNetworkidle,
+	})
+	if err == nil && pageResponse == nil {
+		err = errors.New("no response")
+	}
+
+	if err != nil {
+		j.health = websiteHealthError(j.URL, err)
+
+		return scrapemate.Response{URL: j.URL, Error: err}
+	}
+
+	j.health = NewWebsiteHealth(pageResponse)
+
+	resp := scrapemate.Response{
+		URL:        pageResponse.URL(),
+		StatusCode: pageResponse.Status(),
+		Headers:    make(http.Header, len(pageResponse.Headers())),
+	}
+
+	for k, v := range pageResponse.Headers() {
+		resp.Headers.Add(k, v)
+	}
+
+	resp.Body, resp.Error = pageResponse.Body()
+
+	return resp
 }
 
 func (j *EmailExtractJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
@@ -48,6 +207,15 @@ func (j *EmailExtractJob) Process(ctx context.Context, resp *scrapemate.Response
 	log := scrapemate.GetLoggerFromContext(ctx)
 	log.Info("Processing email job", "url", j.URL)
 
+	j.Entry.Website = j.health
+
+	if j.needsRender {
+		// the entry is written by the render job
+		j.UsageInResults = false
+
+		return nil, []scrapemate.IJob{j.renderJob()}, nil
+	}
+
 	if resp.Error != nil {
 		return j.Entry, nil, nil
 	}
@@ -57,32 +225,92 @@ func (j *EmailExtractJob) Process(ctx context.Context, resp *scrapemate.Response
 		return j.Entry, nil, nil
 	}
 
+	if j.Entry.Website != nil {
+		j.Entry.Website.AnalyzePage(doc, resp.Body)
+	}
+
+	j.Entry.Relevance = ScoreRelevance(doc, j.Relevance.Terms)
+	if j.Entry.Relevance.Score < j.Relevance.MinScore {
+		log.Info("Skipping irrelevant website", "url", j.URL, "score", j.Entry.Relevance.Score)
+
+		j.UsageInResults = false
+
+		return nil, nil, nil
+	}
+
 	emails := docEmailExtractor(doc)
 	if len(emails) == 0 {
 		emails = regexEmailExtractor(resp.Body)
 	}
 
 	j.Entry.Emails = emails
+	if len(emails) > 0 {
+		j.Entry.setFieldSource("emails", SourceWebsite)
+	}
+
 	socialLinks := extractSocialLinks(doc)
 	for key, value := range socialLinks {
 		j.Entry.SocialLinks[key] = value
+		j.Entry.setFieldSource(key, SourceWebsite)
+	}
+
+	pageURL := resp.URL
+	if pageURL == "" {
+		pageURL = j.URL
+	}
+
+	j.Entry.Outreach = ExtractOutreach(doc, resp.Body, pageURL)
+
+	j.Entry.NIPCandidates = ExtractNIPs(resp.Body)
+	if len(j.Entry.NIPCandidates) > 0 && j.Entry.NIPCandidates[0].Confidence >= MinNIPConfidence {
+		j.Entry.NIP = j.Entry.NIPCandidates[0].Value
+		j.Entry.setFieldSource("nip", SourceWebsite)
+	}
+
+	j.Entry.REGONCandidates = ExtractREGONs(resp.Body)
+	if len(j.Entry.REGONCandidates) > 0 {
+		j.Entry.REGON = j.Entry.REGONCandidates[0].Value
 	}
 
-	nip := extractNIP(resp.Body)
-	j.Entry.NIP = nip
+	j.Entry.KRSCandidates = ExtractKRSs(resp.Body)
+	if len(j.Entry.KRSCandidates) > 0 {
+		j.Entry.KRS = j.Entry.KRSCandidates[0].Value
+	}
+
+	// schema.org markup is more reliable than the regular expressions
+	j.Entry.applyStructuredData(ExtractStructuredData(doc))
+
+	if enrichers := j.enrichment.enrichers(); len(enrichers) > 0 && j.Entry.hasRegistryIDs() {
+		// the entry is written by the registry job once it has been enriched
+		j.UsageInResults = false
 
-	if nip != "" {
-		job := NewCEIDGJob(j.Entry)
-		return j.Entry, []scrapemate.IJob{job}, nil
+		return nil, []scrapemate.IJob{NewRegistryJob(j.ID, j.Entry, enrichers)}, nil
 	}
 
 	return j.Entry, nil, nil
 }
 
+// renderJob returns the job reading the website from the rendered DOM. It
+// has the low priority of the Google Maps search jobs, so the place and
+// website jobs waiting go first, while the render jobs take turns with the
+// searches. Like every website visit, it holds its browser page while it
+// waits for the politeness delay of the host and for a render slot.
+func (j *EmailExtractJob) renderJob() *EmailExtractJob {
+	job := NewEmailJob(j.ID, j.Entry, WithEmailJobEnrichment(j.enrichment), WithEmailJobRelevance(j.Relevance))
+	job.Priority = scrapemate.PriorityLow
+	job.Render = true
+
+	return job
+}
+
 func (j *EmailExtractJob) ProcessOnFetchError() bool {
 	return true
 }
 
+func (j *EmailExtractJob) UseInResults() bool {
+	return j.UsageInResults
+}
+
 func docEmailExtractor(doc *goquery.Document) []string {
 	seen := map[string]bool{}
 	var emails []string
@@ -147,154 +375,3 @@ func extractSocialLinks(doc *goquery.Document) map[string]string {
 
 	return socialLinks
 }
-
-func extractNIP(body []byte) string {
-	re := regexp.MustCompile(`((\d{3}[- ]\d{3}[- ]\d{2}[- ]\d{2})|(\d{3}[- ]\d{2}[- ]\d{2}[- ]\d{3}))`)
-	matches := re.Find(body)
-	if matches != nil {
-		return cleanNIP(string(matches))
-	}
-	return ""
-}
-
-func cleanNIP(nip string) string {
-	return strings.ReplaceAll(strings.ReplaceAll(nip, "-", ""), " ", "")
-}
-
-func NewCEIDGJob(entry *Entry) *CEIDGExtractJob {
-	url := fmt.Sprintf("https://api.firmateka.pl/ceidg/firmy?nip=%s", cleanNIP(entry.NIP))
-
-	return &CEIDGExtractJob{
-		Job: scrapemate.Job{
-			Method:     "GET",
-			URL:        url,
-			MaxRetries: 0,
-			Priority:   scrapemate.PriorityHigh,
-		},
-		Entry: entry,
-	}
-}
-
-func (j *CEIDGExtractJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
-	log := scrapemate.GetLoggerFromContext(ctx)
-	log.Info("Processing CEIDG job", "url", j.URL)
-
-	// Załaduj zmienne środowiskowe
-	err := godotenv.Load()
-	if err != nil {
-		log.Error("Error loading .env file")
-		return j.Entry, nil, fmt.Errorf("could not load .env file")
-	}
-
-	// Pobierz klucz API
-	apiKey := "sk-proj-SnkHyNq3bCUZdggjOLwKT3BlbkFJeLxLtaYGV078WROIFpAa"
-
-	if apiKey == "" {
-		log.Error("API key not found in environment variables")
-		return j.Entry, nil, fmt.Errorf("missing API key")
-	}
-
-	// Dodaj debugowanie, aby sprawdzić, czy klucz API został poprawnie załadowany
-	log.Info("Loaded API key", "apiKey", apiKey)
-
-	// Utworzenie zapytania HTTP
-	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest("GET", j.URL, nil)
-	if err != nil {
-		log.Error("Error creating request", "error", err)
-		return j.Entry, nil, err
-	}
-
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
-
-	respAPI, err := client.Do(req)
-	if err != nil {
-		log.Error("Error sending request to Firmateka API", "error", err)
-		return j.Entry, nil, err
-	}
-	defer respAPI.Body.Close()
-
-	log.Info("Response Status Code", "statusCode", respAPI.StatusCode)
-
-	body, err := ioutil.ReadAll(respAPI.Body)
-	if err != nil {
-		log.Error("Error reading response body", "error", err)
-		return j.Entry, nil, err
-	}
-	log.Info("Response Body", "body", string(body))
-
-	// Przetwarzanie odpowiedzi jako JSON
-	var firmatekaResponse struct {
-		Firmy []struct {
-			ID                string `json:"id"`
-			Nazwa             string `json:"nazwa"`
-			AdresDzialalnosci struct {
-				Ulica       string `json:"ulica"`
-				Budynek     string `json:"budynek"`
-				Miasto      string `json:"miasto"`
-				Wojewodztwo string `json:"wojewodztwo"`
-				Powiat      string `json:"powiat"`
-				Gmina       string `json:"gmina"`
-				Kraj        string `json:"kraj"`
-				Kod         string `json:"kod"`
-			} `json:"adresDzialalnosci"`
-			Wlasciciel struct {
-				Imie     string `json:"imie"`
-				Nazwisko string `json:"nazwisko"`
-				Nip      string `json:"nip"`
-				Regon    string `json:"regon"`
-			} `json:"wlasciciel"`
-			DataRozpoczecia string `json:"dataRozpoczecia"`
-			Status          string `json:"status"`
-			Link            string `json:"link"`
-		} `json:"firmy"`
-	}
-
-	err = json.Unmarshal(body, &firmatekaResponse)
-	if err != nil {
-		log.Error("Error unmarshalling Firmateka response", "error", err)
-		return j.Entry, nil, err
-	}
-
-	// Sprawdzamy, czy odpowiedź zawiera dane firmy
-	if len(firmatekaResponse.Firmy) > 0 {
-		firma := firmatekaResponse.Firmy[0]
-
-		ceidgData := fmt.Sprintf(`{
-			"id": "%s",
-			"nazwa": "%s",
-			"wlasciciel": {
-				"imie": "%s",
-				"nazwisko": "%s",
-				"nip": "%s",
-				"regon": "%s"
-			},
-			"adresDzialalnosci": {
-				"ulica": "%s",
-				"budynek": "%s",
-				"miasto": "%s",
-				"wojewodztwo": "%s",
-				"powiat": "%s",
-				"gmina": "%s",
-				"kraj": "%s",
-				"kod": "%s"
-			},
-			"dataRozpoczecia": "%s",
-			"status": "%s",
-			"link": "%s"
-		}`,
-			firma.ID,
-			firma.Nazwa,
-			firma.Wlasciciel.Imie, firma.Wlasciciel.Nazwisko, firma.Wlasciciel.Nip, firma.Wlasciciel.Regon,
-			firma.AdresDzialalnosci.Ulica, firma.AdresDzialalnosci.Budynek, firma.AdresDzialalnosci.Miasto,
-			firma.AdresDzialalnosci.Wojewodztwo, firma.AdresDzialalnosci.Powiat, firma.AdresDzialalnosci.Gmina,
-			firma.AdresDzialalnosci.Kraj, firma.AdresDzialalnosci.Kod,
-			firma.DataRozpoczecia, firma.Status, firma.Link)
-
-		j.Entry.CEIDG = ceidgData
-	} else {
-		log.Info("Firmateka data not found", "url", j.URL)
-	}
-
-	return j.Entry, nil, nil
-}
@@ -0,0 +1,227 @@
+package gmaps
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/gosom/scrapemate"
+	"github.com/playwright-community/playwright-go"
+)
+
+// Enricher adds data from an external source to an entry. Enrichers run in
+// a chain after the website of the business has been processed, so they can
+// use the NIP, REGON and KRS numbers found there and the data added by the
+// enrichers before them.
+type Enricher interface {
+	// Name identifies the enricher in logs.
+	Name() string
+	// Enrich updates the entry. Having nothing to look up is not an error.
+	Enrich(ctx context.Context, e *Entry) error
+}
+
+// RegistryExtractJob runs the enricher chain for an entry. The chain is not
+// stored with the job, Enrichment.Attach sets it again.
+type RegistryExtractJob struct {
+	scrapemate.Job
+	Entry *Entry
+
+	enrichers []Enricher
+}
+
+func NewRegistryJob(parentID string, entry *Entry, enrichers []Enricher) *RegistryExtractJob {
+	key := entry.NIP
+	if key == "" {
+		key = entry.REGON
+	}
+
+	if key == "" {
+		key = entry.KRS
+	}
+
+	return &RegistryExtractJob{
+		Job: scrapemate.Job{
+			// the ID is derived from the email job and the identifier, so
+			// that pushing the job again after a resume is a no-op
+			ID:         uuid.NewSHA1(uuid.NameSpaceURL, []byte(parentID+"|"+key)).String(),
+			ParentID:   parentID,
+			Method:     "GET",
+			URL:        "registry:" + cleanNIP(key),
+			MaxRetries: 0,
+			Priority:   scrapemate.PriorityHigh,
+		},
+		Entry:     entry,
+		enrichers: enrichers,
+	}
+}
+
+// BrowserActions does not open any page. The lookups are done by the
+// registry clients in Process, so that API keys never go through the browser.
+func (j *RegistryExtractJob) BrowserActions(_ context.Context, _ playwright.Page) scrapemate.Response {
+	return scrapemate.Response{URL: j.URL, StatusCode: 200}
+}
+
+func (j *RegistryExtractJob) ProcessOnFetchError() bool {
+	return true
+}
+
+func (j *RegistryExtractJob) Process(ctx context.Context, _ *scrapemate.Response) (any, []scrapemate.IJob, error) {
+	log := scrapemate.GetLoggerFromContext(ctx)
+	log.Info("Processing registry job", "nip", j.Entry.NIP, "regon", j.Entry.REGON, "krs", j.Entry.KRS)
+
+	// A failed lookup must not drop the entry, it is written without the
+	// data of that registry instead.
+	for _, enricher := range j.enrichers {
+		if err := enricher.Enrich(ctx, j.Entry); err != nil {
+			log.Error("registry lookup failed", "registry", enricher.Name(), "error", err)
+		}
+	}
+
+	return j.Entry, nil, nil
+}
+
+// hasRegistryIDs reports whether the entry has an identifier the registries
+// can be searched by.
+func (e *Entry) hasRegistryIDs() bool {
+	return e.NIP != "" || e.REGON != "" || e.KRS != ""
+}
+
+// NewCEIDGEnricher looks up the NIP of the entry in CEIDG.
+func NewCEIDGEnricher(client RegistryClient) Enricher {
+	return &ceidgEnricher{client: client}
+}
+
+type ceidgEnricher struct {
+	client RegistryClient
+}
+
+func (c *ceidgEnricher) Name() string {
+	return SourceCEIDG
+}
+
+func (c *ceidgEnricher) Enrich(ctx context.Context, e *Entry) error {
+	if e.NIP == "" {
+		return nil
+	}
+
+	firms, err := c.client.LookupNIP(ctx, e.NIP)
+	if err != nil {
+		return err
+	}
+
+	log := scrapemate.GetLoggerFromContext(ctx)
+
+	if len(firms) == 0 {
+		log.Info("Firmateka data not found", "nip", e.NIP)
+
+		return nil
+	}
+
+	regs := make([]*CompanyRegistration, len(firms))
+	for i := range firms {
+		regs[i] = firms[i].Registration()
+	}
+
+	e.CEIDG = bestRegistration(e, regs)
+	e.mergeRegistration(ctx, e.CEIDG)
+
+	return nil
+}
+
+// NewGUSEnricher looks up the NIP, or the REGON when there is no NIP, of the
+// entry in the GUS REGON database.
+func NewGUSEnricher(client BIRClient) Enricher {
+	return &gusEnricher{client: client}
+}
+
+type gusEnricher struct {
+	client BIRClient
+}
+
+func (g *gusEnricher) Name() string {
+	return SourceGUS
+}
+
+func (g *gusEnricher) Enrich(ctx context.Context, e *Entry) error {
+	var (
+		records []BIRRecord
+		err     error
+	)
+
+	switch {
+	case e.NIP != "":
+		records, err = g.client.SearchNIP(ctx, e.NIP)
+	case e.REGON != "":
+		records, err = g.client.SearchREGON(ctx, e.REGON)
+	default:
+		return nil
+	}
+
+	if err != nil || len(records) == 0 {
+		return err
+	}
+
+	regs := make([]*CompanyRegistration, len(records))
+	for i := range records {
+		regs[i] = records[i].Registration()
+	}
+
+	e.GUS = bestRegistration(e, regs)
+	e.mergeRegistration(ctx, e.GUS)
+
+	return nil
+}
+
+// NewKRSEnricher looks up the KRS number of the entry in the KRS.
+func NewKRSEnricher(client KRSClient) Enricher {
+	return &krsEnricher{client: client}
+}
+
+type krsEnricher struct {
+	client KRSClient
+}
+
+func (k *krsEnricher) Name() string {
+	return SourceKRS
+}
+
+func (k *krsEnricher) Enrich(ctx context.Context, e *Entry) error {
+	if e.KRS == "" {
+		return nil
+	}
+
+	record, err := k.client.LookupKRS(ctx, e.KRS)
+	if err != nil || record == nil {
+		return err
+	}
+
+	e.KRSRegistry = bestRegistration(e, []*CompanyRegistration{record.Registration()})
+	e.mergeRegistration(ctx, e.KRSRegistry)
+
+	return nil
+}
+
+// mergeRegistration adds the identifiers of a registration to the entry,
+// attributed to the registry. Low confidence matches are not merged since
+// the record may belong to another company.
+func (e *Entry) mergeRegistration(ctx context.Context, reg *CompanyRegistration) {
+	if reg.LowConfidence {
+		scrapemate.GetLoggerFromContext(ctx).Warn("registry record does not match the place",
+			"registry", reg.Source, "title", e.Title, "name", reg.Name, "score", reg.MatchScore)
+
+		return
+	}
+
+	mergeID(&e.NIP, &e.NIPCandidates, cleanNIP(reg.NIP), reg.Source)
+	mergeID(&e.REGON, &e.REGONCandidates, reg.REGON, reg.Source)
+	mergeID(&e.KRS, &e.KRSCandidates, reg.KRS, reg.Source)
+}
+
+func mergeID(primary *string, candidates *[]IDCandidate, value, source string) {
+	if value == "" {
+		return
+	}
+
+	*candidates = addCandidate(*candidates, value, 1, source)
+	sortCandidates(*candidates)
+	*primary = (*candidates)[0].Value
+}
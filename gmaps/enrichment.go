@@ -0,0 +1,101 @@
+package gmaps
+
+import "github.com/gosom/scrapemate"
+
+// Enrichment holds the services used to visit the website of the business
+// and to enrich the entry afterwards. A nil Enrichment, or a nil service,
+// skips the corresponding step.
+type Enrichment struct {
+	// Registry is used to look up the extracted NIP in CEIDG.
+	Registry RegistryClient
+	// GUS is used to look up the extracted NIP or REGON in the GUS REGON
+	// database.
+	GUS BIRClient
+	// KRS is used to look up the extracted KRS number in the KRS.
+	KRS KRSClient
+	// VIES is used to check the NIP as an EU VAT number.
+	VIES VIESClient
+	// Cache keeps the website pages between runs. The registry clients are
+	// wrapped with it by the caller.
+	Cache *DiskCache
+	// Politeness keeps the website visits within robots.txt and the per
+	// host limits.
+	Politeness *Politeness
+	// Renderer reads the rendered DOM of the websites built with
+	// JavaScript when their HTML has nothing to extract.
+	Renderer *Renderer
+	// Limits are shared by the registry clients, their counters are
+	// printed in the run summary.
+	Limits *RegistryLimits
+}
+
+// enrichers returns the registry enricher chain. GUS goes first since it
+// knows every kind of business, KRS goes next to last. VIES goes last so it
+// checks the NIP confirmed by the registries.
+func (e *Enrichment) enrichers() []Enricher {
+	if e == nil {
+		return nil
+	}
+
+	var enrichers []Enricher
+
+	if e.GUS != nil {
+		enrichers = append(enrichers, NewGUSEnricher(e.GUS))
+	}
+
+	if e.Registry != nil {
+		enrichers = append(enrichers, NewCEIDGEnricher(e.Registry))
+	}
+
+	if e.KRS != nil {
+		enrichers = append(enrichers, NewKRSEnricher(e.KRS))
+	}
+
+	if e.VIES != nil {
+		enrichers = append(enrichers, NewVIESEnricher(e.VIES))
+	}
+
+	return enrichers
+}
+
+// cache returns the disk cache, nil when there is none.
+func (e *Enrichment) cache() *DiskCache {
+	if e == nil {
+		return nil
+	}
+
+	return e.Cache
+}
+
+// politeness returns the website politeness rules, nil when there are none.
+func (e *Enrichment) politeness() *Politeness {
+	if e == nil {
+		return nil
+	}
+
+	return e.Politeness
+}
+
+// renderer returns the JavaScript rendering fallback, nil when there is none.
+func (e *Enrichment) renderer() *Renderer {
+	if e == nil {
+		return nil
+	}
+
+	return e.Renderer
+}
+
+// Attach sets the services of a job read back from a job provider, which
+// stores the settings of the job but not the services of the run.
+func (e *Enrichment) Attach(job scrapemate.IJob) {
+	switch j := job.(type) {
+	case *GmapJob:
+		j.enrichment = e
+	case *PlaceJob:
+		j.enrichment = e
+	case *EmailExtractJob:
+		j.enrichment = e
+	case *RegistryExtractJob:
+		j.enrichers = e.enrichers()
+	}
+}
@@ -4,21 +4,38 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 type Entry struct {
-	ID            string   `json:"input_id"`
-	Link          string   `json:"link"`
-	Title         string   `json:"title"`
-	Address       Address  `json:"complete_address"`
-	City          string   `json:"city"`
-	WebSite       string   `json:"web_site"`
-	Phone         string   `json:"phone"`
-	Emails        []string `json:"emails"`
-	SocialLinks   map[string]string `json:"social_links"` // Added JSON tag
-	NIP           string   `json:"nip"`
-	CEIDG         string   `json:"ceidg"`
+	ID              string               `json:"input_id"`
+	Query           string               `json:"query"`
+	Link            string               `json:"link"`
+	Title           string               `json:"title"`
+	Address         Address              `json:"complete_address"`
+	City            string               `json:"city"`
+	Latitude        float64              `json:"latitude"`
+	Longitude       float64              `json:"longitude"`
+	WebSite         string               `json:"web_site"`
+	Phone           string               `json:"phone"`
+	Emails          []string             `json:"emails"`
+	SocialLinks     map[string]string    `json:"social_links"` // Added JSON tag
+	NIP             string               `json:"nip"`
+	NIPCandidates   []IDCandidate        `json:"nip_candidates"`
+	REGON           string               `json:"regon"`
+	REGONCandidates []IDCandidate        `json:"regon_candidates"`
+	KRS             string               `json:"krs"`
+	KRSCandidates   []IDCandidate        `json:"krs_candidates"`
+	CEIDG           *CompanyRegistration `json:"ceidg"`
+	GUS             *CompanyRegistration `json:"gus"`
+	KRSRegistry     *CompanyRegistration `json:"krs_registry"`
+	VAT             *VATStatus           `json:"vat"`
+	OpeningHours    []string             `json:"opening_hours"`
+	FieldSources    map[string]string    `json:"field_sources"`
+	Website         *WebsiteHealth       `json:"website_health"`
+	Outreach        *Outreach            `json:"outreach"`
+	Relevance       *Relevance           `json:"relevance"`
 }
 
 type Address struct {
@@ -38,13 +55,117 @@ func (e *Entry) CsvHeaders() []string {
 		"instagram",
 		"twitter",
 		"nip",
-		"ceidg",
+		"regon",
+		"krs",
+		"ceidg_name",
+		"ceidg_owner",
+		"ceidg_nip",
+		"ceidg_regon",
+		"ceidg_address",
+		"ceidg_start_date",
+		"ceidg_status",
+		"ceidg_link",
+		"ceidg_match_score",
+		"ceidg_low_confidence",
+		"gus_name",
+		"gus_address",
+		"gus_status",
+		"krs_name",
+		"krs_legal_form",
+		"krs_address",
+		"registry_match",
+		"vat_valid",
+		"vat_name",
+		"opening_hours",
+		"website_status",
+		"website_https",
+		"website_tls_valid",
+		"website_response_ms",
+		"website_cms",
+		"website_viewport",
+		"website_last_modified",
+		"contact_form",
+		"contact_form_fields",
+		"whatsapp",
+		"messenger",
+		"telegram",
+		"chat_widgets",
+		"language",
+		"relevance_score",
+		"relevance_matched_terms",
+		"latitude",
+		"longitude",
 	}
 }
 
 func (e *Entry) CsvRow() []string {
 	address := fmt.Sprintf("%s %s", e.Address.Street, e.Address.Number)
 
+	ceidg, gus, krs := e.CEIDG, e.GUS, e.KRSRegistry
+	matchScore, lowConfidence := "", ""
+
+	if ceidg != nil {
+		matchScore = strconv.FormatFloat(ceidg.MatchScore, 'f', 2, 64)
+		lowConfidence = strconv.FormatBool(ceidg.LowConfidence)
+	} else {
+		ceidg = &CompanyRegistration{}
+	}
+
+	if gus == nil {
+		gus = &CompanyRegistration{}
+	}
+
+	if krs == nil {
+		krs = &CompanyRegistration{}
+	}
+
+	vatValid, vatName := "", ""
+
+	if e.VAT != nil {
+		vatValid = strconv.FormatBool(e.VAT.Valid)
+		vatName = e.VAT.Name
+	}
+
+	website := e.Website
+	if website == nil {
+		website = &WebsiteHealth{}
+	}
+
+	var websiteStatus, websiteHTTPS, websiteTLSValid, websiteResponse, websiteViewport string
+
+	if e.Website != nil {
+		websiteStatus = strconv.Itoa(website.StatusCode)
+		websiteHTTPS = strconv.FormatBool(website.HTTPS)
+		websiteTLSValid = strconv.FormatBool(website.TLSValid)
+		websiteResponse = strconv.FormatInt(website.ResponseTime, 10)
+		websiteViewport = strconv.FormatBool(website.Viewport)
+	}
+
+	cms := strings.TrimSpace(website.CMS + " " + website.CMSVersion)
+
+	outreach := e.Outreach
+	if outreach == nil {
+		outreach = &Outreach{}
+	}
+
+	var language, relevanceScore, matchedTerms string
+	if e.Relevance != nil {
+		language = e.Relevance.Language
+		relevanceScore = strconv.FormatFloat(e.Relevance.Score, 'f', 2, 64)
+		matchedTerms = strings.Join(e.Relevance.Matched, ", ")
+	}
+
+	var latitude, longitude string
+	if e.HasCoordinates() {
+		latitude = strconv.FormatFloat(e.Latitude, 'f', 7, 64)
+		longitude = strconv.FormatFloat(e.Longitude, 'f', 7, 64)
+	}
+
+	var contactForm ContactForm
+	if len(outreach.ContactForms) > 0 {
+		contactForm = outreach.ContactForms[0]
+	}
+
 	return []string{
 		e.Title,
 		address,
@@ -56,10 +177,79 @@ func (e *Entry) CsvRow() []string {
 		e.SocialLinks["instagram"],
 		e.SocialLinks["twitter"],
 		e.NIP,
-		e.CEIDG,
+		e.REGON,
+		e.KRS,
+		ceidg.Name,
+		ceidg.Owner(),
+		ceidg.NIP,
+		ceidg.REGON,
+		ceidg.Address.String(),
+		ceidg.StartDate,
+		ceidg.Status,
+		ceidg.Link,
+		matchScore,
+		lowConfidence,
+		gus.Name,
+		gus.Address.String(),
+		gus.Status,
+		krs.Name,
+		krs.LegalForm,
+		krs.Address.String(),
+		registryMatch(e.GUS, e.KRSRegistry),
+		vatValid,
+		vatName,
+		strings.Join(e.OpeningHours, "; "),
+		websiteStatus,
+		websiteHTTPS,
+		websiteTLSValid,
+		websiteResponse,
+		cms,
+		websiteViewport,
+		website.LastModified,
+		contactForm.Action,
+		strings.Join(contactForm.Fields, ", "),
+		outreach.WhatsApp,
+		outreach.Messenger,
+		outreach.Telegram,
+		strings.Join(outreach.ChatWidgets, ", "),
+		language,
+		relevanceScore,
+		matchedTerms,
+		latitude,
+		longitude,
 	}
 }
 
+// registryMatch describes how the registry records match the place, for
+// example "gus: 0.92 (name, street, city); krs: 0.40 low confidence (name)".
+func registryMatch(regs ...*CompanyRegistration) string {
+	var parts []string
+
+	for _, reg := range regs {
+		if reg == nil {
+			continue
+		}
+
+		part := reg.Source + ": " + strconv.FormatFloat(reg.MatchScore, 'f', 2, 64)
+		if reg.LowConfidence {
+			part += " low confidence"
+		}
+
+		if len(reg.MatchedOn) > 0 {
+			part += " (" + strings.Join(reg.MatchedOn, ", ") + ")"
+		}
+
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// HasCoordinates reports whether the location of the place is known.
+func (e *Entry) HasCoordinates() bool {
+	return e.Latitude != 0 || e.Longitude != 0
+}
+
 func (e *Entry) IsWebsiteValidForEmail() bool {
 	if e.WebSite == "" {
 		return false
@@ -96,13 +286,15 @@ func EntryFromJSON(raw []byte) (Entry, error) {
 	}
 
 	entry := Entry{
-		ID:     getNthElementAndCast[string](darray, 0),
-		Link:   getNthElementAndCast[string](darray, 1),
-		Title:  getNthElementAndCast[string](darray, 11),
-		City:   getNthElementAndCast[string](darray, 183, 1, 3),
-		WebSite: getNthElementAndCast[string](darray, 7, 0),
-		Phone:  getNthElementAndCast[string](darray, 178, 0, 0),
-		Emails: getNthElementAndCast[[]string](darray, 5),
+		ID:        getNthElementAndCast[string](darray, 0),
+		Link:      getNthElementAndCast[string](darray, 1),
+		Title:     getNthElementAndCast[string](darray, 11),
+		City:      getNthElementAndCast[string](darray, 183, 1, 3),
+		WebSite:   getNthElementAndCast[string](darray, 7, 0),
+		Phone:     getNthElementAndCast[string](darray, 178, 0, 0),
+		Emails:    getNthElementAndCast[[]string](darray, 5),
+		Latitude:  getNthElementAndCast[float64](darray, 9, 2),
+		Longitude: getNthElementAndCast[float64](darray, 9, 3),
 	}
 
 	// Extract the complete address
@@ -198,4 +390,4 @@ func getNthElementAndCast[T any](arr []any, indexes ...int) T {
 
 func stringSliceToString(s []string) string {
 	return strings.Join(s, ", ")
-}
\ No newline at end of file
+}
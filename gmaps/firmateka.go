@@ -0,0 +1,155 @@
+package gmaps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gosom/scrapemate"
+)
+
+const (
+	firmatekaDefaultURL     = "https://api.firmateka.pl"
+	firmatekaDefaultTimeout = 10 * time.Second
+
+	envFirmatekaAPIKey  = "FIRMATEKA_API_KEY"
+	envFirmatekaURL     = "FIRMATEKA_URL"
+	envFirmatekaTimeout = "FIRMATEKA_TIMEOUT"
+)
+
+// ErrMissingAPIKey is returned when a registry client is created without
+// an API key.
+var ErrMissingAPIKey = errors.New("missing API key")
+
+// FirmatekaConfig configures the Firmateka backed RegistryClient.
+type FirmatekaConfig struct {
+	URL     string
+	APIKey  string
+	Timeout time.Duration
+	// Limiter limits the rate of the requests and retries them, nil
+	// sends them as they come.
+	Limiter *RegistryLimiter
+}
+
+// FirmatekaConfigFromEnv reads the configuration from the FIRMATEKA_API_KEY,
+// FIRMATEKA_URL and FIRMATEKA_TIMEOUT environment variables.
+func FirmatekaConfigFromEnv() (FirmatekaConfig, error) {
+	return LoadFirmatekaConfig("")
+}
+
+// LoadFirmatekaConfig reads the configuration from a file in the .env format
+// using the same keys as FirmatekaConfigFromEnv. Keys missing from the file
+// are taken from the environment.
+func LoadFirmatekaConfig(path string) (FirmatekaConfig, error) {
+	values, err := readSettings(path, envFirmatekaAPIKey, envFirmatekaURL, envFirmatekaTimeout)
+	if err != nil {
+		return FirmatekaConfig{}, err
+	}
+
+	cfg := FirmatekaConfig{
+		URL:    values[envFirmatekaURL],
+		APIKey: strings.TrimSpace(values[envFirmatekaAPIKey]),
+	}
+
+	if cfg.URL == "" {
+		cfg.URL = firmatekaDefaultURL
+	}
+
+	cfg.Timeout, err = durationSetting(values, envFirmatekaTimeout, firmatekaDefaultTimeout)
+	if err != nil {
+		return FirmatekaConfig{}, err
+	}
+
+	return cfg, nil
+}
+
+// String implements fmt.Stringer and never prints the API key.
+func (c FirmatekaConfig) String() string {
+	key := ""
+	if c.APIKey != "" {
+		key = redacted
+	}
+
+	return fmt.Sprintf("FirmatekaConfig{URL: %s, APIKey: %s, Timeout: %s}", c.URL, key, c.Timeout)
+}
+
+var _ RegistryClient = (*FirmatekaClient)(nil)
+
+// FirmatekaClient looks up CEIDG firms using the Firmateka API.
+type FirmatekaClient struct {
+	cfg    FirmatekaConfig
+	client *http.Client
+}
+
+func NewFirmatekaClient(cfg FirmatekaConfig) (*FirmatekaClient, error) {
+	if cfg.APIKey == "" {
+		return nil, ErrMissingAPIKey
+	}
+
+	if cfg.URL == "" {
+		cfg.URL = firmatekaDefaultURL
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = firmatekaDefaultTimeout
+	}
+
+	return &FirmatekaClient{
+		cfg:    cfg,
+		client: registryHTTPClient(cfg.Timeout, cfg.Limiter),
+	}, nil
+}
+
+// LookupURL returns the URL used to look up nip.
+func (c *FirmatekaClient) LookupURL(nip string) string {
+	return strings.TrimRight(c.cfg.URL, "/") + "/ceidg/firmy?nip=" + url.QueryEscape(cleanNIP(nip))
+}
+
+func (c *FirmatekaClient) LookupNIP(ctx context.Context, nip string) ([]CEIDGFirm, error) {
+	log := NewRedactingLogger(scrapemate.GetLoggerFromContext(ctx), c.cfg.APIKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.LookupURL(nip), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, redactError(err, c.cfg.APIKey)
+	}
+
+	defer resp.Body.Close()
+
+	log.Debug("Firmateka response", "nip", nip, "statusCode", resp.StatusCode)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("firmateka: unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var firmatekaResponse struct {
+		Firmy []CEIDGFirm `json:"firmy"`
+	}
+
+	if err := json.Unmarshal(body, &firmatekaResponse); err != nil {
+		return nil, fmt.Errorf("firmateka: %w", err)
+	}
+
+	return firmatekaResponse.Firmy, nil
+}
@@ -0,0 +1,60 @@
+package gmaps_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+func Test_FirmatekaClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/ceidg/firmy", r.URL.Path)
+		require.Equal(t, "Bearer secret-key", r.Header.Get("Authorization"))
+
+		if r.URL.Query().Get("nip") != "5260250274" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"firmy":[{"id":"1","nazwa":"Pompy \"Kowalski\"","wlasciciel":{"nip":"5260250274"}}]}`))
+	}))
+	defer srv.Close()
+
+	client, err := gmaps.NewFirmatekaClient(gmaps.FirmatekaConfig{URL: srv.URL, APIKey: "secret-key"})
+	require.NoError(t, err)
+
+	firms, err := client.LookupNIP(context.Background(), "526-025-02-74")
+	require.NoError(t, err)
+	require.Len(t, firms, 1)
+	require.Equal(t, `Pompy "Kowalski"`, firms[0].Nazwa)
+
+	firms, err = client.LookupNIP(context.Background(), "1234563218")
+	require.NoError(t, err)
+	require.Empty(t, firms)
+}
+
+func Test_NewFirmatekaClientWithoutKey(t *testing.T) {
+	_, err := gmaps.NewFirmatekaClient(gmaps.FirmatekaConfig{})
+	require.ErrorIs(t, err, gmaps.ErrMissingAPIKey)
+}
+
+func Test_LoadFirmatekaConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.env")
+	require.NoError(t, os.WriteFile(path, []byte("FIRMATEKA_API_KEY=from-file\nFIRMATEKA_TIMEOUT=3s\n"), 0o600))
+
+	cfg, err := gmaps.LoadFirmatekaConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, "from-file", cfg.APIKey)
+	require.Equal(t, 3*time.Second, cfg.Timeout)
+	require.Equal(t, "https://api.firmateka.pl", cfg.URL)
+	require.NotContains(t, cfg.String(), "from-file")
+}
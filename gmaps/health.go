@@ -0,0 +1,169 @@
+package gmaps
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/playwright-community/playwright-go"
+)
+
+var (
+	generatorVersionRe = regexp.MustCompile(`^(.*?)\s+v?(\d+(?:\.\d+)+)`)
+	copyrightRe        = regexp.MustCompile(`(?i)(?:©|\(c\)|copyright)\s*(?:\d{4}\s*[-–]\s*)?((?:19|20)\d{2})`)
+)
+
+// cmsMarkers detect a CMS or shop platform by a fragment of the page HTML
+// when there is no generator meta tag.
+var cmsMarkers = []struct {
+	name   string
+	marker string
+}{
+	{"WordPress", "/wp-content/"},
+	{"WordPress", "/wp-includes/"},
+	{"Joomla", "/media/jui/"},
+	{"Joomla", "/components/com_"},
+	{"Drupal", "/sites/default/files/"},
+	{"Drupal", "drupal-settings-json"},
+	{"Wix", "static.wixstatic.com"},
+	{"Shopify", "cdn.shopify.com"},
+	{"Squarespace", "static1.squarespace.com"},
+	{"Webflow", "data-wf-page"},
+	{"PrestaShop", "prestashop"},
+	{"Magento", "/static/frontend/"},
+	{"Shoper", "shoper.pl"},
+	{"IdoSell", "idosell"},
+}
+
+// WebsiteHealth describes the state and the technology of a business website.
+type WebsiteHealth struct {
+	// StatusCode is the status of the last response, zero when the website
+	// could not be reached.
+	StatusCode int `json:"status_code"`
+	// Error tells why the website could not be reached.
+	Error    string `json:"error,omitempty"`
+	FinalURL string `json:"final_url"`
+	// Redirects lists the URLs redirected from, in order.
+	Redirects []string `json:"redirects"`
+	HTTPS     bool     `json:"https"`
+	// TLSValid reports whether the certificate of the final URL is trusted
+	// and valid, TLSError tells why the browser refused it.
+	TLSValid     bool       `json:"tls_valid"`
+	TLSError     string     `json:"tls_error,omitempty"`
+	TLSExpiresAt *time.Time `json:"tls_expires_at,omitempty"`
+	// ResponseTime is the time until the last byte of the final page.
+	ResponseTime int64  `json:"response_time_ms"`
+	CMS          string `json:"cms"`
+	CMSVersion   string `json:"cms_version"`
+	Generator    string `json:"generator"`
+	Viewport     bool   `json:"viewport"`
+	// LastModified is the Last-Modified header or the modification time
+	// from the page meta tags.
+	LastModified  string `json:"last_modified"`
+	CopyrightYear int    `json:"copyright_year,omitempty"`
+	// Rendered reports that the page was read from the DOM rendered by
+	// the browser, since its HTML was built by JavaScript.
+	Rendered bool `json:"rendered"`
+}
+
+// NewWebsiteHealth records the status, the redirect chain, the TLS state
+// and the response time of the website from the response of the browser
+// navigation to it, so the website is not requested again to profile it.
+// The browser refuses a certificate which does not verify, so the TLS
+// state of a page it loaded over HTTPS is valid.
+func NewWebsiteHealth(resp playwright.Response) *WebsiteHealth {
+	health := &WebsiteHealth{
+		StatusCode:   resp.Status(),
+		FinalURL:     resp.URL(),
+		LastModified: resp.Headers()["last-modified"],
+	}
+
+	if u, err := url.Parse(health.FinalURL); err == nil {
+		health.HTTPS = u.Scheme == "https"
+	}
+
+	if req := resp.Request(); req != nil {
+		for from := req.RedirectedFrom(); from != nil; from = from.RedirectedFrom() {
+			health.Redirects = append([]string{from.URL()}, health.Redirects...)
+		}
+
+		if timing := req.Timing(); timing != nil && timing.ResponseEnd >= 0 {
+			health.ResponseTime = int64(timing.ResponseEnd)
+		}
+	}
+
+	if details, err := resp.SecurityDetails(); err == nil && details != nil && details.ValidTo != nil {
+		expiresAt := time.Unix(int64(*details.ValidTo), 0).UTC()
+		health.TLSExpiresAt = &expiresAt
+	}
+
+	health.TLSValid = health.HTTPS && (health.TLSExpiresAt == nil || time.Now().Before(*health.TLSExpiresAt))
+
+	return health
+}
+
+// websiteHealthError records why the browser could not load the website,
+// a certificate it refused as the TLS error.
+func websiteHealthError(rawURL string, err error) *WebsiteHealth {
+	health := &WebsiteHealth{Error: err.Error()}
+
+	if u, parseErr := url.Parse(rawURL); parseErr == nil {
+		health.HTTPS = u.Scheme == "https"
+	}
+
+	if msg := err.Error(); strings.Contains(msg, "ERR_CERT_") || strings.Contains(msg, "ERR_SSL_") {
+		health.HTTPS = true
+		health.TLSError = msg
+	}
+
+	return health
+}
+
+// AnalyzePage adds what the HTML of the page tells about the website.
+func (h *WebsiteHealth) AnalyzePage(doc *goquery.Document, body []byte) {
+	h.Viewport = doc.Find(`meta[name="viewport" i]`).Length() > 0
+
+	h.Generator = strings.TrimSpace(doc.Find(`meta[name="generator" i]`).First().AttrOr("content", ""))
+	if h.Generator != "" {
+		h.CMS = h.Generator
+		if m := generatorVersionRe.FindStringSubmatch(h.Generator); m != nil {
+			h.CMS, h.CMSVersion = strings.TrimSpace(m[1]), m[2]
+		}
+
+		h.CMS = strings.TrimSuffix(h.CMS, "!")
+	}
+
+	if h.CMS == "" {
+		lower := strings.ToLower(string(body))
+
+		for _, m := range cmsMarkers {
+			if strings.Contains(lower, strings.ToLower(m.marker)) {
+				h.CMS = m.name
+
+				break
+			}
+		}
+	}
+
+	if h.LastModified == "" {
+		for _, property := range []string{"article:modified_time", "og:updated_time", "last-modified"} {
+			selector := fmt.Sprintf(`meta[property=%q], meta[name=%q], meta[http-equiv=%q i]`, property, property, property)
+			if v := strings.TrimSpace(doc.Find(selector).First().AttrOr("content", "")); v != "" {
+				h.LastModified = v
+
+				break
+			}
+		}
+	}
+
+	// the copyright year in the footer is a hint of the last update too
+	for _, m := range copyrightRe.FindAllStringSubmatch(doc.Find("body").Text(), -1) {
+		if year, err := strconv.Atoi(m[1]); err == nil && year > h.CopyrightYear {
+			h.CopyrightYear = year
+		}
+	}
+}
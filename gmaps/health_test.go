@@ -0,0 +1,127 @@
+package gmaps_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+const wordpressPage = `<html><head>
+<meta name="generator" content="WordPress 5.2.4">
+<meta property="article:modified_time" content="2019-03-01T10:00:00+00:00">
+</head><body><footer>© 2015-2019 Firma</footer></body></html>`
+
+// navigation is a browser navigation response, only the methods the
+// health is read from are implemented.
+type navigation struct {
+	playwright.Response
+	url     string
+	status  int
+	headers map[string]string
+	request *navigationRequest
+	validTo *float64
+	body    string
+}
+
+func (n *navigation) URL() string                 { return n.url }
+func (n *navigation) Status() int                 { return n.status }
+func (n *navigation) Headers() map[string]string  { return n.headers }
+func (n *navigation) Request() playwright.Request { return n.request }
+func (n *navigation) Body() ([]byte, error)       { return []byte(n.body), nil }
+
+func (n *navigation) SecurityDetails() (*playwright.ResponseSecurityDetailsResult, error) {
+	if n.validTo == nil {
+		return nil, nil
+	}
+
+	return &playwright.ResponseSecurityDetailsResult{ValidTo: n.validTo}, nil
+}
+
+type navigationRequest struct {
+	playwright.Request
+	url    string
+	from   *navigationRequest
+	timing *playwright.RequestTiming
+}
+
+func (r *navigationRequest) URL() string { return r.url }
+
+func (r *navigationRequest) Timing() *playwright.RequestTiming { return r.timing }
+
+func (r *navigationRequest) RedirectedFrom() playwright.Request {
+	if r.from == nil {
+		return nil
+	}
+
+	return r.from
+}
+
+func Test_NewWebsiteHealth(t *testing.T) {
+	validTo := float64(time.Now().Add(90 * 24 * time.Hour).Unix())
+
+	health := gmaps.NewWebsiteHealth(&navigation{
+		url:     "https://www.pompy.pl/home",
+		status:  http.StatusOK,
+		headers: map[string]string{"last-modified": "Wed, 21 Oct 2015 07:28:00 GMT"},
+		request: &navigationRequest{
+			url:    "https://www.pompy.pl/home",
+			timing: &playwright.RequestTiming{ResponseEnd: 312.5},
+			from: &navigationRequest{
+				url:  "https://www.pompy.pl/",
+				from: &navigationRequest{url: "http://pompy.pl/"},
+			},
+		},
+		validTo: &validTo,
+	})
+
+	require.Empty(t, health.Error)
+	require.Equal(t, http.StatusOK, health.StatusCode)
+	require.Equal(t, "https://www.pompy.pl/home", health.FinalURL)
+	require.Equal(t, []string{"http://pompy.pl/", "https://www.pompy.pl/"}, health.Redirects)
+	require.True(t, health.HTTPS)
+	require.True(t, health.TLSValid)
+	require.NotNil(t, health.TLSExpiresAt)
+	require.Equal(t, int64(312), health.ResponseTime)
+	require.Equal(t, "Wed, 21 Oct 2015 07:28:00 GMT", health.LastModified)
+}
+
+func Test_NewWebsiteHealthPlainHTTP(t *testing.T) {
+	health := gmaps.NewWebsiteHealth(&navigation{
+		url:     "http://pompy.pl/",
+		status:  http.StatusNotFound,
+		headers: map[string]string{},
+		request: &navigationRequest{url: "http://pompy.pl/"},
+	})
+
+	require.Equal(t, http.StatusNotFound, health.StatusCode)
+	require.Empty(t, health.Redirects)
+	require.False(t, health.HTTPS)
+	require.False(t, health.TLSValid)
+	require.Nil(t, health.TLSExpiresAt)
+}
+
+func Test_WebsiteHealthAnalyzePage(t *testing.T) {
+	health := &gmaps.WebsiteHealth{}
+	health.AnalyzePage(newDocument(t, wordpressPage), []byte(wordpressPage))
+
+	require.Equal(t, "WordPress", health.CMS)
+	require.Equal(t, "5.2.4", health.CMSVersion)
+	require.False(t, health.Viewport)
+	require.Equal(t, "2019-03-01T10:00:00+00:00", health.LastModified)
+	require.Equal(t, 2019, health.CopyrightYear)
+
+	page := `<html><head><meta name="Viewport" content="width=device-width">
+<link rel="stylesheet" href="/wp-content/themes/x/style.css"></head><body></body></html>`
+
+	health = &gmaps.WebsiteHealth{}
+	health.AnalyzePage(newDocument(t, page), []byte(page))
+
+	require.Equal(t, "WordPress", health.CMS)
+	require.Empty(t, health.CMSVersion)
+	require.True(t, health.Viewport)
+}
@@ -13,17 +13,26 @@ import (
 	"github.com/playwright-community/playwright-go"
 )
 
+type GmapJobOptions func(*GmapJob)
+
 type GmapJob struct {
 	scrapemate.Job
 
 	MaxDepth     int
 	LangCode     string
 	ExtractEmail bool
+	// Query is the search the places found by the job are written with.
+	Query string
+	// Relevance is exported, like the other settings, so that it is kept
+	// when a job provider stores the job.
+	Relevance RelevanceConfig
+
+	enrichment *Enrichment
+	// cities are left out of the default relevance terms.
+	cities []string
 }
 
-func NewGmapJob(id, langCode, query string, maxDepth int, extractEmail bool) *GmapJob {
-	query = url.QueryEscape(query)
-
+func NewGmapJob(id, langCode, query string, maxDepth int, extractEmail bool, opts ...GmapJobOptions) *GmapJob {
 	const (
 		maxRetries = 3
 		prio       = scrapemate.PriorityLow
@@ -37,7 +46,7 @@ func NewGmapJob(id, langCode, query string, maxDepth int, extractEmail bool) *Gm
 		Job: scrapemate.Job{
 			ID:         id,
 			Method:     http.MethodGet,
-			URL:        "https://www.google.com/maps/search/" + query,
+			URL:        "https://www.google.com/maps/search/" + url.QueryEscape(query),
 			URLParams:  map[string]string{"hl": langCode},
 			MaxRetries: maxRetries,
 			Priority:   prio,
@@ -45,11 +54,46 @@ func NewGmapJob(id, langCode, query string, maxDepth int, extractEmail bool) *Gm
 		MaxDepth:     maxDepth,
 		LangCode:     langCode,
 		ExtractEmail: extractEmail,
+		Query:        query,
+	}
+
+	for _, opt := range opts {
+		opt(&job)
+	}
+
+	// the websites are scored against the query without its city unless the
+	// job has its own terms, any website of the city would match it
+	if len(job.Relevance.Terms) == 0 {
+		job.Relevance.Terms = RelevanceTerms(QueryPhrase(query, job.cities))
 	}
 
 	return &job
 }
 
+// WithEnrichment sets the services used to enrich the places found by the job.
+func WithEnrichment(e *Enrichment) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.enrichment = e
+	}
+}
+
+// WithRelevance sets the terms the websites of the places are scored
+// against and the minimal score of the places written to the results.
+func WithRelevance(cfg RelevanceConfig) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.Relevance = cfg
+	}
+}
+
+// WithRelevanceCities sets the cities the queries end with, for example those
+// of the miasta.txt template, which are left out of the default relevance
+// terms.
+func WithRelevanceCities(cities []string) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.cities = cities
+	}
+}
+
 func (j *GmapJob) UseInResults() bool {
 	return false
 }
@@ -70,12 +114,12 @@ func (j *GmapJob) Process(ctx context.Context, resp *scrapemate.Response) (any,
 	var next []scrapemate.IJob
 
 	if strings.Contains(resp.URL, "/maps/place/") {
-		placeJob := NewPlaceJob(j.ID, j.LangCode, resp.URL, j.ExtractEmail)
+		placeJob := NewPlaceJob(j.ID, j.LangCode, resp.URL, j.ExtractEmail, WithPlaceJobEnrichment(j.enrichment), WithPlaceJobRelevance(j.Relevance), WithPlaceJobQuery(j.Query))
 		next = append(next, placeJob)
 	} else {
 		doc.Find(`div[role=feed] div[jsaction]>a`).Each(func(_ int, s *goquery.Selection) {
 			if href := s.AttrOr("href", ""); href != "" {
-				nextJob := NewPlaceJob(j.ID, j.LangCode, href, j.ExtractEmail)
+				nextJob := NewPlaceJob(j.ID, j.LangCode, href, j.ExtractEmail, WithPlaceJobEnrichment(j.enrichment), WithPlaceJobRelevance(j.Relevance), WithPlaceJobQuery(j.Query))
 				next = append(next, nextJob)
 			}
 		})
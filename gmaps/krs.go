@@ -0,0 +1,202 @@
+package gmaps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	krsDefaultURL     = "https://api-krs.ms.gov.pl"
+	krsDefaultTimeout = 10 * time.Second
+
+	envKRSURL     = "KRS_API_URL"
+	envKRSTimeout = "KRS_API_TIMEOUT"
+)
+
+// KRSClient looks up companies in the National Court Register (KRS).
+type KRSClient interface {
+	// LookupKRS returns the current extract for the KRS number, or nil
+	// when the number is not registered.
+	LookupKRS(ctx context.Context, krs string) (*KRSRecord, error)
+}
+
+// KRSRecord is the part of the current KRS extract the scraper uses.
+type KRSRecord struct {
+	Odpis struct {
+		NaglowekA struct {
+			NumerKRS            string `json:"numerKRS"`
+			DataRejestracjiWKRS string `json:"dataRejestracjiWKRS"`
+		} `json:"naglowekA"`
+		Dane struct {
+			Dzial1 struct {
+				DanePodmiotu struct {
+					FormaPrawna    string `json:"formaPrawna"`
+					Nazwa          string `json:"nazwa"`
+					Identyfikatory struct {
+						NIP   string `json:"nip"`
+						REGON string `json:"regon"`
+					} `json:"identyfikatory"`
+				} `json:"danePodmiotu"`
+				SiedzibaIAdres struct {
+					Siedziba struct {
+						Wojewodztwo string `json:"wojewodztwo"`
+						Powiat      string `json:"powiat"`
+						Gmina       string `json:"gmina"`
+					} `json:"siedziba"`
+					Adres struct {
+						Ulica       string `json:"ulica"`
+						NrDomu      string `json:"nrDomu"`
+						NrLokalu    string `json:"nrLokalu"`
+						Miejscowosc string `json:"miejscowosc"`
+						KodPocztowy string `json:"kodPocztowy"`
+						Kraj        string `json:"kraj"`
+					} `json:"adres"`
+				} `json:"siedzibaIAdres"`
+			} `json:"dzial1"`
+		} `json:"dane"`
+	} `json:"odpis"`
+}
+
+// Registration converts the extract to a CompanyRegistration.
+func (r *KRSRecord) Registration() *CompanyRegistration {
+	podmiot := r.Odpis.Dane.Dzial1.DanePodmiotu
+	siedziba := r.Odpis.Dane.Dzial1.SiedzibaIAdres
+
+	building := siedziba.Adres.NrDomu
+	if siedziba.Adres.NrLokalu != "" {
+		building += "/" + siedziba.Adres.NrLokalu
+	}
+
+	// the REGON in KRS is often the 9 digit one padded with zeros
+	regon := podmiot.Identyfikatory.REGON
+	if len(regon) == regonLongLen && strings.HasSuffix(regon, "00000") {
+		regon = regon[:regonShortLen]
+	}
+
+	return &CompanyRegistration{
+		Source:    SourceKRS,
+		ID:        r.Odpis.NaglowekA.NumerKRS,
+		Name:      podmiot.Nazwa,
+		LegalForm: podmiot.FormaPrawna,
+		NIP:       podmiot.Identyfikatory.NIP,
+		REGON:     regon,
+		KRS:       r.Odpis.NaglowekA.NumerKRS,
+		Address: RegistrationAddress{
+			Street:      siedziba.Adres.Ulica,
+			Building:    building,
+			PostalCode:  siedziba.Adres.KodPocztowy,
+			City:        siedziba.Adres.Miejscowosc,
+			Commune:     siedziba.Siedziba.Gmina,
+			County:      siedziba.Siedziba.Powiat,
+			Voivodeship: siedziba.Siedziba.Wojewodztwo,
+			Country:     siedziba.Adres.Kraj,
+		},
+		StartDate: r.Odpis.NaglowekA.DataRejestracjiWKRS,
+	}
+}
+
+// KRSConfig configures the KRS API client. The API is public and needs no key.
+type KRSConfig struct {
+	URL     string
+	Timeout time.Duration
+	// Limiter limits the rate of the requests and retries them, nil
+	// sends them as they come.
+	Limiter *RegistryLimiter
+}
+
+// LoadKRSConfig reads the KRS_API_URL and KRS_API_TIMEOUT settings from a
+// file in the .env format, or from the environment when path is empty.
+func LoadKRSConfig(path string) (KRSConfig, error) {
+	values, err := readSettings(path, envKRSURL, envKRSTimeout)
+	if err != nil {
+		return KRSConfig{}, err
+	}
+
+	cfg := KRSConfig{URL: values[envKRSURL]}
+
+	cfg.Timeout, err = durationSetting(values, envKRSTimeout, krsDefaultTimeout)
+	if err != nil {
+		return KRSConfig{}, err
+	}
+
+	return cfg, nil
+}
+
+var _ KRSClient = (*KRSAPIClient)(nil)
+
+// KRSAPIClient uses the open API of the Ministry of Justice.
+type KRSAPIClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewKRSAPIClient(cfg KRSConfig) *KRSAPIClient {
+	if cfg.URL == "" {
+		cfg.URL = krsDefaultURL
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = krsDefaultTimeout
+	}
+
+	return &KRSAPIClient{
+		baseURL: strings.TrimRight(cfg.URL, "/"),
+		client:  registryHTTPClient(cfg.Timeout, cfg.Limiter),
+	}
+}
+
+// LookupKRS searches the register of entrepreneurs first and the register
+// of associations and foundations next.
+func (c *KRSAPIClient) LookupKRS(ctx context.Context, krs string) (*KRSRecord, error) {
+	for _, rejestr := range []string{"P", "S"} {
+		record, err := c.odpis(ctx, krs, rejestr)
+		if err != nil || record != nil {
+			return record, err
+		}
+	}
+
+	return nil, nil
+}
+
+func (c *KRSAPIClient) odpis(ctx context.Context, krs, rejestr string) (*KRSRecord, error) {
+	u := c.baseURL + "/api/krs/OdpisAktualny/" + url.PathEscape(krs) + "?rejestr=" + rejestr + "&format=json"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNoContent:
+		return nil, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("krs: unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var record KRSRecord
+	if err := json.Unmarshal(body, &record); err != nil {
+		return nil, fmt.Errorf("krs: %w", err)
+	}
+
+	return &record, nil
+}
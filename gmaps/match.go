@@ -0,0 +1,231 @@
+package gmaps
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MatchThreshold is the score below which a registry record is flagged as
+// a low confidence match for the entry.
+const MatchThreshold = 0.5
+
+const (
+	matchWeightName   = 0.5
+	matchWeightStreet = 0.3
+	matchWeightCity   = 0.2
+
+	// matchMaxWithoutName caps the score of a record whose name could not
+	// be compared, e.g. an entry named only "Firma Usługowa": the address
+	// alone does not tell which company is there.
+	matchMaxWithoutName = 0.4
+
+	// fuzzyTokenRatio is the minimal similarity for two tokens to be
+	// considered the same word, e.g. with a typo or a different inflection.
+	fuzzyTokenRatio = 0.8
+	fuzzyMinLen     = 4
+)
+
+var diacritics = strings.NewReplacer(
+	"ą", "a", "ć", "c", "ę", "e", "ł", "l", "ń", "n", "ó", "o", "ś", "s", "ź", "z", "ż", "z",
+	"ä", "a", "ö", "o", "ü", "u", "ß", "ss", "é", "e", "á", "a", "í", "i", "ú", "u", "č", "c", "š", "s", "ž", "z",
+)
+
+// nameStopWords are legal forms and generic words that say nothing about
+// which company a name refers to.
+var nameStopWords = map[string]bool{
+	"sp": true, "z": true, "o": true, "oo": true, "spolka": true, "spolki": true, "s": true, "c": true,
+	"sc": true, "sa": true, "j": true, "k": true, "jawna": true, "komandytowa": true, "akcyjna": true,
+	"ograniczona": true, "odpowiedzialnoscia": true,
+	"firma": true, "fhu": true, "phu": true, "ppuh": true, "fphu": true, "pphu": true, "uslugi": true,
+	"uslugowa": true, "handlowa": true, "handlowo": true, "i": true, "w": true, "oraz": true,
+}
+
+var addressStopWords = map[string]bool{
+	"ul": true, "ulica": true, "al": true, "aleja": true, "aleje": true, "pl": true, "plac": true,
+	"os": true, "osiedle": true, "lok": true, "m": true,
+}
+
+// MatchScore returns how likely it is that the registry record describes the
+// business of the entry, in the range [0, 1]. Name, street and city are
+// compared ignoring case, diacritics and punctuation. Parts that are missing
+// on either side are left out of the score; without the name the score stays
+// below MatchThreshold.
+func MatchScore(e *Entry, r *CompanyRegistration) float64 {
+	score, _ := matchScore(e, r)
+
+	return score
+}
+
+// matchScore returns the MatchScore of the record and the parts it compared:
+// "name", "street" and "city".
+func matchScore(e *Entry, r *CompanyRegistration) (float64, []string) {
+	if e == nil || r == nil {
+		return 0, nil
+	}
+
+	var (
+		score, weights float64
+		compared       []string
+	)
+
+	title := matchTokens(e.Title, nameStopWords)
+	if name := matchTokens(r.Name+" "+r.Owner(), nameStopWords); len(title) > 0 && len(name) > 0 {
+		score += matchWeightName * tokenOverlap(title, name)
+		weights += matchWeightName
+		compared = append(compared, "name")
+	}
+
+	street := matchTokens(e.Address.Street+" "+e.Address.Number, addressStopWords)
+	if regStreet := matchTokens(r.Address.Street+" "+r.Address.Building, addressStopWords); len(street) > 0 && len(regStreet) > 0 {
+		score += matchWeightStreet * tokenOverlap(street, regStreet)
+		weights += matchWeightStreet
+		compared = append(compared, "street")
+	}
+
+	city := normalizeForMatch(e.City)
+	if regCity := normalizeForMatch(r.Address.City); city != "" && regCity != "" {
+		if city == regCity || tokenSimilar(city, regCity) {
+			score += matchWeightCity
+		}
+
+		weights += matchWeightCity
+		compared = append(compared, "city")
+	}
+
+	if weights == 0 {
+		return 0, nil
+	}
+
+	score /= weights
+	if compared[0] != "name" && score > matchMaxWithoutName {
+		score = matchMaxWithoutName
+	}
+
+	return score, compared
+}
+
+// bestRegistration returns the registration that matches the entry best,
+// with its match score filled in.
+func bestRegistration(e *Entry, regs []*CompanyRegistration) *CompanyRegistration {
+	var best *CompanyRegistration
+
+	for _, reg := range regs {
+		reg.MatchScore, reg.MatchedOn = matchScore(e, reg)
+
+		if best == nil || reg.MatchScore > best.MatchScore {
+			best = reg
+		}
+	}
+
+	if best != nil {
+		best.LowConfidence = best.MatchScore < MatchThreshold
+	}
+
+	return best
+}
+
+// normalizeForMatch lowercases s, strips the diacritics and replaces
+// everything that is not a letter or a digit with a space.
+func normalizeForMatch(s string) string {
+	s = diacritics.Replace(strings.ToLower(s))
+
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+
+		return ' '
+	}, s)
+
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func matchTokens(s string, stopWords map[string]bool) []string {
+	var tokens []string
+
+	for _, t := range strings.Fields(normalizeForMatch(s)) {
+		if !stopWords[t] {
+			tokens = append(tokens, t)
+		}
+	}
+
+	return tokens
+}
+
+// tokenOverlap returns the share of tokens of the shorter list that have
+// a similar token in the other one.
+func tokenOverlap(a, b []string) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	if len(a) == 0 {
+		return 0
+	}
+
+	matched := 0
+
+	for _, ta := range a {
+		for _, tb := range b {
+			if ta == tb || tokenSimilar(ta, tb) {
+				matched++
+
+				break
+			}
+		}
+	}
+
+	return float64(matched) / float64(len(a))
+}
+
+func tokenSimilar(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < fuzzyMinLen || len(rb) < fuzzyMinLen {
+		return false
+	}
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+
+	return 1-float64(levenshtein(ra, rb))/float64(longest) >= fuzzyTokenRatio
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
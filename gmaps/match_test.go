@@ -0,0 +1,35 @@
+package gmaps_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+func Test_MatchScore(t *testing.T) {
+	entry := &gmaps.Entry{
+		Title:   "Pompy Ciepła Łukasz Żółć",
+		Address: gmaps.Address{Street: "Długa", Number: "5"},
+		City:    "Łódź",
+	}
+
+	same := &gmaps.CompanyRegistration{
+		Name:    "ŁUKASZ ŻÓŁĆ USŁUGI INSTALACYJNE",
+		Address: gmaps.RegistrationAddress{Street: "ul. Dluga", Building: "5", City: "Lodz"},
+	}
+	agency := &gmaps.CompanyRegistration{
+		Name:    "WebStudio Sp. z o.o.",
+		Address: gmaps.RegistrationAddress{Street: "Marszałkowska", Building: "100", City: "Warszawa"},
+	}
+
+	require.Greater(t, gmaps.MatchScore(entry, same), 0.8)
+	require.Less(t, gmaps.MatchScore(entry, agency), gmaps.MatchThreshold)
+	require.Zero(t, gmaps.MatchScore(&gmaps.Entry{}, same))
+
+	// a name made of legal forms and generic words says nothing, the
+	// address alone is not enough
+	generic := &gmaps.Entry{Title: "Firma Usługowa", Address: entry.Address, City: entry.City}
+	require.Less(t, gmaps.MatchScore(generic, same), gmaps.MatchThreshold)
+}
@@ -0,0 +1,209 @@
+package gmaps
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// IDCandidate is a business identifier found on a website together with a
+// confidence score in the range (0, 1].
+type IDCandidate struct {
+	Value      string  `json:"value"`
+	Confidence float64 `json:"confidence"`
+	// Source is where the identifier was found, e.g. SourceWebsite.
+	Source string `json:"source"`
+}
+
+var (
+	nipRe = regexp.MustCompile(`\b(PL[ -]?)?(\d{3}[- ]?\d{3}[- ]?\d{2}[- ]?\d{2}|\d{3}[- ]?\d{2}[- ]?\d{2}[- ]?\d{3})\b`)
+	// idLabelRe matches the labels that usually precede an identifier, or
+	// a phone number. Only the closest label before a number is taken into
+	// account.
+	idLabelRe = regexp.MustCompile(`(?i)\b(nip|vat(?:[ -]?(?:id|ue|eu))?|regon|krs|tel(?:efon)?|fax|faks|kom(?:órka)?)\b|\+48`)
+
+	nipWeights = []int{6, 5, 7, 2, 3, 4, 5, 6, 7}
+)
+
+const (
+	// labelWindow is how many bytes before a number are searched for a label.
+	labelWindow = 64
+
+	confidenceSeparated   = 0.4
+	confidenceUnseparated = 0.2
+	confidenceLabel       = 0.4
+	confidencePrefix      = 0.3
+	// confidencePhone is the confidence of a number labelled as a phone
+	// number, which passes the NIP checksum once in eleven times.
+	confidencePhone = 0.1
+)
+
+// MinNIPConfidence is the confidence a NIP found on a website needs to be
+// taken as the NIP of the business. The candidates below it are only kept
+// in Entry.NIPCandidates, since a bare 10 digit number is as likely to be a
+// phone number.
+const MinNIPConfidence = 0.5
+
+// ValidNIP reports whether nip is a 10 digit NIP with a correct control digit.
+func ValidNIP(nip string) bool {
+	nip = cleanNIP(nip)
+
+	if len(nip) != len(nipWeights)+1 || !isDigits(nip) {
+		return false
+	}
+
+	sum := 0
+	for i, w := range nipWeights {
+		sum += int(nip[i]-'0') * w
+	}
+
+	const mod = 11
+
+	control := sum % mod
+
+	return control != 10 && control == int(nip[len(nipWeights)]-'0')
+}
+
+// ExtractNIPs returns all the checksum valid NIP numbers found in body,
+// best candidates first.
+//
+// Numbers preceded by a NIP or VAT label and numbers in the PL prefixed form
+// score higher, numbers preceded by a phone label score lower. Numbers
+// labelled as REGON or KRS, or that are a fragment of a longer number
+// (phones, bank accounts), are skipped.
+func ExtractNIPs(body []byte) []IDCandidate {
+	var candidates []IDCandidate
+
+	for _, m := range nipRe.FindAllSubmatchIndex(body, -1) {
+		start, end := m[0], m[1]
+		if partOfLongerNumber(body, start, end) {
+			continue
+		}
+
+		nip := cleanNIP(string(body[m[4]:m[5]]))
+		if !ValidNIP(nip) {
+			continue
+		}
+
+		confidence := confidenceUnseparated
+		if bytes.ContainsAny(body[m[4]:m[5]], "- ") {
+			confidence = confidenceSeparated
+		}
+
+		switch precedingLabel(body, start) {
+		case "nip", "vat":
+			confidence += confidenceLabel
+		case "phone":
+			confidence = confidencePhone
+		case "regon", "krs":
+			continue
+		}
+
+		if m[2] >= 0 {
+			confidence += confidencePrefix
+		}
+
+		candidates = addCandidate(candidates, nip, confidence, SourceWebsite)
+	}
+
+	sortCandidates(candidates)
+
+	return candidates
+}
+
+// precedingLabel returns the normalized identifier label closest to pos,
+// looking back at most labelWindow bytes, or an empty string. A label that
+// is already followed by another number does not count.
+func precedingLabel(body []byte, pos int) string {
+	from := pos - labelWindow
+	if from < 0 {
+		from = 0
+	}
+
+	matches := idLabelRe.FindAllIndex(body[from:pos], -1)
+	if len(matches) == 0 {
+		return ""
+	}
+
+	last := matches[len(matches)-1]
+	if bytes.ContainsAny(body[from+last[1]:pos], "0123456789") {
+		return ""
+	}
+
+	label := strings.ToLower(string(body[from+last[0] : from+last[1]]))
+
+	switch {
+	case strings.HasPrefix(label, "vat"):
+		return "vat"
+	case label == "+48" || strings.HasPrefix(label, "tel") || strings.HasPrefix(label, "fa") || strings.HasPrefix(label, "kom"):
+		return "phone"
+	}
+
+	return label
+}
+
+// partOfLongerNumber reports whether the match body[start:end] continues a
+// number before or after it, e.g. a group of digits inside a bank account.
+func partOfLongerNumber(body []byte, start, end int) bool {
+	isSep := func(b byte) bool { return b == '-' || b == ' ' }
+
+	if start > 0 && isDigit(body[start-1]) {
+		return true
+	}
+
+	if start > 1 && isSep(body[start-1]) && isDigit(body[start-2]) {
+		return true
+	}
+
+	if end < len(body) && isDigit(body[end]) {
+		return true
+	}
+
+	return end+1 < len(body) && isSep(body[end]) && isDigit(body[end+1])
+}
+
+// addCandidate adds value to candidates. When value is already there the
+// higher confidence, together with its source, is kept.
+func addCandidate(candidates []IDCandidate, value string, confidence float64, source string) []IDCandidate {
+	if confidence > 1 {
+		confidence = 1
+	}
+
+	for i := range candidates {
+		if candidates[i].Value == value {
+			if confidence > candidates[i].Confidence {
+				candidates[i].Confidence = confidence
+				candidates[i].Source = source
+			}
+
+			return candidates
+		}
+	}
+
+	return append(candidates, IDCandidate{Value: value, Confidence: confidence, Source: source})
+}
+
+func sortCandidates(candidates []IDCandidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Confidence > candidates[j].Confidence
+	})
+}
+
+func cleanNIP(nip string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(nip, "-", ""), " ", "")
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+
+	return s != ""
+}
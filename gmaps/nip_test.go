@@ -0,0 +1,91 @@
+package gmaps_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+func Test_ValidNIP(t *testing.T) {
+	require.True(t, gmaps.ValidNIP("5260250274"))
+	require.True(t, gmaps.ValidNIP("526-025-02-74"))
+	require.True(t, gmaps.ValidNIP("526 02 50 274"))
+	require.False(t, gmaps.ValidNIP("1234567890"))
+	require.False(t, gmaps.ValidNIP("526025027"))
+	require.False(t, gmaps.ValidNIP("52602502x4"))
+}
+
+func Test_ExtractNIPs(t *testing.T) {
+	t.Run("label and prefix win over bare numbers", func(t *testing.T) {
+		body := []byte(`<p>Tel. 123-456-32-18</p>
+			<p>Konto: 61 1090 1014 0000 0712 1981 2874</p>
+			<p><strong>NIP:</strong> PL5213017228</p>
+			<p>1234563218</p>`)
+
+		got := gmaps.ExtractNIPs(body)
+
+		require.Len(t, got, 2)
+		require.Equal(t, "5213017228", got[0].Value)
+		require.InDelta(t, 0.9, got[0].Confidence, 0.001)
+		require.Equal(t, "1234563218", got[1].Value)
+		require.InDelta(t, 0.2, got[1].Confidence, 0.001)
+	})
+
+	t.Run("numbers labelled as phone numbers score lowest", func(t *testing.T) {
+		for _, body := range []string{
+			`Tel. 526-025-02-74`,
+			`Telefon: 526 025 02 74`,
+			`fax 526-025-02-74`,
+			`(+48) 526-025-02-74`,
+		} {
+			got := gmaps.ExtractNIPs([]byte(body))
+
+			require.Len(t, got, 1, body)
+			require.InDelta(t, 0.1, got[0].Confidence, 0.001, body)
+			require.Less(t, got[0].Confidence, gmaps.MinNIPConfidence, body)
+		}
+	})
+
+	t.Run("a NIP label after a phone number wins", func(t *testing.T) {
+		got := gmaps.ExtractNIPs([]byte(`Tel. 22 123 45 67, NIP: 526-025-02-74`))
+
+		require.Len(t, got, 1)
+		require.InDelta(t, 0.8, got[0].Confidence, 0.001)
+	})
+
+	t.Run("invalid checksum is dropped", func(t *testing.T) {
+		require.Empty(t, gmaps.ExtractNIPs([]byte(`NIP: 123-456-78-90`)))
+	})
+
+	t.Run("numbers labelled as REGON or KRS are skipped", func(t *testing.T) {
+		require.Empty(t, gmaps.ExtractNIPs([]byte(`KRS: 5260250274`)))
+	})
+
+	t.Run("fragments of longer numbers are skipped", func(t *testing.T) {
+		require.Empty(t, gmaps.ExtractNIPs([]byte(`+48 526-025-02-74-11`)))
+	})
+}
+
+func Test_EmailExtractJobNIPConfidence(t *testing.T) {
+	for body, nip := range map[string]string{
+		`<p>Tel. 526-025-02-74</p>`:                  "",
+		`<p>526-025-02-74</p>`:                       "",
+		`<p>NIP: 526-025-02-74</p>`:                  "5260250274",
+		`<p>Tel. 22 123 45 67, NIP PL5260250274</p>`: "5260250274",
+		`<p>PL5260250274</p>`:                        "5260250274",
+	} {
+		entry := &gmaps.Entry{Title: "Pompy Kowalski", WebSite: "https://kowalski.pl", SocialLinks: map[string]string{}}
+		resp := &scrapemate.Response{Body: []byte(body), Document: newDocument(t, body)}
+
+		_, _, err := gmaps.NewEmailJob("place", entry).Process(context.Background(), resp)
+		require.NoError(t, err)
+
+		// the candidates below the threshold are kept
+		require.Equal(t, nip, entry.NIP, body)
+		require.Len(t, entry.NIPCandidates, 1, body)
+	}
+}
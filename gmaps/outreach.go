@@ -0,0 +1,177 @@
+package gmaps
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// chatWidgets detect a live chat widget by a fragment of the page HTML.
+var chatWidgets = []struct {
+	name   string
+	marker string
+}{
+	{"Tawk.to", "embed.tawk.to"},
+	{"Tidio", "code.tidio.co"},
+	{"LiveChat", "cdn.livechatinc.com"},
+	{"Smartsupp", "smartsuppchat.com"},
+	{"Intercom", "widget.intercom.io"},
+	{"Zendesk", "static.zdassets.com"},
+	{"Crisp", "client.crisp.chat"},
+	{"HubSpot", "js.usemessages.com"},
+	{"JivoChat", "code.jivosite.com"},
+	{"Drift", "js.driftt.com"},
+	{"LiveAgent", "ladesk.com"},
+	{"Freshchat", "wchat.freshchat.com"},
+	{"Facebook Messenger", "fb-customerchat"},
+}
+
+// formEmbeds are the hosted form builders embedded in an iframe.
+var formEmbeds = []string{
+	"docs.google.com/forms",
+	"forms.gle",
+	"typeform.com",
+	"jotform.com",
+	"tally.so",
+	"formularze.pl",
+	"forms.office.com",
+}
+
+var (
+	whatsAppRe  = regexp.MustCompile(`(?i)^(?:https?://)?(?:wa\.me/|(?:api|web)\.whatsapp\.com/send/?\?(?:.*&)?phone=|whatsapp://send\?(?:.*&)?phone=)\+?(\d{6,15})`)
+	messengerRe = regexp.MustCompile(`(?i)^(?:https?://)?(?:m\.me/|(?:www\.)?messenger\.com/t/)([\w.\-]+)`)
+	telegramRe  = regexp.MustCompile(`(?i)^(?:https?://)?(?:t\.me/|telegram\.me/|tg://resolve\?domain=)([\w+]+)`)
+)
+
+// ContactForm is a form on the website a message can be sent with.
+type ContactForm struct {
+	// URL is the page the form is on, Action is where it is sent to and
+	// Method how, empty for an embedded form.
+	URL    string   `json:"url"`
+	Action string   `json:"action"`
+	Method string   `json:"method"`
+	Fields []string `json:"fields"`
+}
+
+// Outreach lists the ways to reach a business other than its email and
+// phone: contact forms, live chat widgets and messenger deep links.
+type Outreach struct {
+	ContactForms []ContactForm `json:"contact_forms"`
+	// WhatsApp, Messenger and Telegram are normalised links opening a
+	// conversation, for example https://wa.me/48601234567.
+	WhatsApp    string   `json:"whatsapp"`
+	Messenger   string   `json:"messenger"`
+	Telegram    string   `json:"telegram"`
+	ChatWidgets []string `json:"chat_widgets"`
+}
+
+// ExtractOutreach finds the outreach channels on the page at pageURL. It
+// returns nil when there are none.
+func ExtractOutreach(doc *goquery.Document, body []byte, pageURL string) *Outreach {
+	var o Outreach
+
+	base, _ := url.Parse(pageURL)
+
+	doc.Find("form").Each(func(_ int, s *goquery.Selection) {
+		if fields, ok := contactFormFields(s); ok {
+			o.ContactForms = append(o.ContactForms, ContactForm{
+				URL:    pageURL,
+				Action: resolveURL(base, s.AttrOr("action", "")),
+				Method: strings.ToUpper(s.AttrOr("method", "get")),
+				Fields: fields,
+			})
+		}
+	})
+
+	doc.Find("iframe[src]").Each(func(_ int, s *goquery.Selection) {
+		src := resolveURL(base, s.AttrOr("src", ""))
+		for _, embed := range formEmbeds {
+			if strings.Contains(src, embed) {
+				o.ContactForms = append(o.ContactForms, ContactForm{URL: pageURL, Action: src})
+
+				break
+			}
+		}
+	})
+
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href := strings.TrimSpace(s.AttrOr("href", ""))
+
+		if m := whatsAppRe.FindStringSubmatch(href); m != nil {
+			setFirst(&o.WhatsApp, "https://wa.me/"+m[1])
+		}
+
+		if m := messengerRe.FindStringSubmatch(href); m != nil {
+			setFirst(&o.Messenger, "https://m.me/"+m[1])
+		}
+
+		if m := telegramRe.FindStringSubmatch(href); m != nil {
+			setFirst(&o.Telegram, "https://t.me/"+m[1])
+		}
+	})
+
+	lower := strings.ToLower(string(body))
+
+	for _, w := range chatWidgets {
+		if strings.Contains(lower, w.marker) {
+			o.ChatWidgets = appendUnique(o.ChatWidgets, w.name)
+		}
+	}
+
+	if len(o.ContactForms) == 0 && o.WhatsApp == "" && o.Messenger == "" && o.Telegram == "" && len(o.ChatWidgets) == 0 {
+		return nil
+	}
+
+	return &o
+}
+
+// contactFormFields returns the names of the fields of a form a message can
+// be sent with. Search, login and newsletter forms are left out: a contact
+// form has a message box or at least three fields one of which is an email
+// or a phone.
+func contactFormFields(form *goquery.Selection) ([]string, bool) {
+	if form.AttrOr("role", "") == "search" || form.Find(`input[type="password" i], input[type="search" i]`).Length() > 0 {
+		return nil, false
+	}
+
+	var (
+		fields  []string
+		contact bool
+	)
+
+	form.Find("input, select, textarea").Each(func(_ int, s *goquery.Selection) {
+		kind := strings.ToLower(s.AttrOr("type", "text"))
+
+		switch kind {
+		case "hidden", "submit", "button", "reset", "image":
+			return
+		case "email", "tel":
+			contact = true
+		}
+
+		name := s.AttrOr("name", "")
+		setFirst(&name, s.AttrOr("id", ""), s.AttrOr("placeholder", ""), kind)
+
+		fields = appendUnique(fields, name)
+	})
+
+	hasMessage := form.Find("textarea").Length() > 0
+
+	return fields, hasMessage || (contact && len(fields) >= 3)
+}
+
+func resolveURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if base == nil {
+		return ref
+	}
+
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+
+	return u.String()
+}
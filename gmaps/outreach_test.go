@@ -0,0 +1,82 @@
+package gmaps_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+const outreachPage = `<html><body>
+<form role="search" action="/szukaj"><input type="search" name="q"></form>
+<form action="/newsletter"><input type="email" name="newsletter_email"><button>Zapisz</button></form>
+<form action="/wp-json/contact-form-7/v1/feedback" method="post">
+  <input type="hidden" name="_wpcf7" value="5">
+  <input type="text" name="your-name">
+  <input type="email" name="your-email">
+  <textarea name="your-message"></textarea>
+  <input type="submit" value="Wyślij">
+</form>
+<iframe src="https://docs.google.com/forms/d/e/abc/viewform?embedded=true"></iframe>
+<a href="https://api.whatsapp.com/send?phone=48601234567&text=Dzie%C5%84%20dobry">WhatsApp</a>
+<a href="https://wa.me/48700000000">WhatsApp 2</a>
+<a href="http://m.me/pompykowalski">Messenger</a>
+<a href="https://t.me/pompy_kowalski">Telegram</a>
+<script src="//code.tidio.co/abcdef.js" async></script>
+</body></html>`
+
+func Test_ExtractOutreach(t *testing.T) {
+	o := gmaps.ExtractOutreach(newDocument(t, outreachPage), []byte(outreachPage), "https://kowalski.pl/kontakt")
+	require.NotNil(t, o)
+
+	require.Equal(t, []gmaps.ContactForm{
+		{
+			URL:    "https://kowalski.pl/kontakt",
+			Action: "https://kowalski.pl/wp-json/contact-form-7/v1/feedback",
+			Method: "POST",
+			Fields: []string{"your-name", "your-email", "your-message"},
+		},
+		{
+			URL:    "https://kowalski.pl/kontakt",
+			Action: "https://docs.google.com/forms/d/e/abc/viewform?embedded=true",
+		},
+	}, o.ContactForms)
+
+	// the first link of every messenger wins
+	require.Equal(t, "https://wa.me/48601234567", o.WhatsApp)
+	require.Equal(t, "https://m.me/pompykowalski", o.Messenger)
+	require.Equal(t, "https://t.me/pompy_kowalski", o.Telegram)
+	require.Equal(t, []string{"Tidio"}, o.ChatWidgets)
+}
+
+func Test_ExtractOutreachNone(t *testing.T) {
+	page := `<html><body><form><input type="email" name="email"></form><a href="/kontakt">Kontakt</a></body></html>`
+
+	require.Nil(t, gmaps.ExtractOutreach(newDocument(t, page), []byte(page), "https://kowalski.pl"))
+}
+
+func Test_EmailExtractJobOutreach(t *testing.T) {
+	entry := &gmaps.Entry{Title: "Pompy Kowalski", WebSite: "https://kowalski.pl", SocialLinks: map[string]string{}}
+
+	resp := &scrapemate.Response{Body: []byte(outreachPage), Document: newDocument(t, outreachPage)}
+
+	_, _, err := gmaps.NewEmailJob("parent", entry).Process(context.Background(), resp)
+	require.NoError(t, err)
+	require.NotNil(t, entry.Outreach)
+	require.Equal(t, "https://kowalski.pl/wp-json/contact-form-7/v1/feedback", entry.Outreach.ContactForms[0].Action)
+
+	row := map[string]string{}
+	for i, header := range entry.CsvHeaders() {
+		row[header] = entry.CsvRow()[i]
+	}
+
+	require.Equal(t, "https://kowalski.pl/wp-json/contact-form-7/v1/feedback", row["contact_form"])
+	require.Equal(t, "your-name, your-email, your-message", row["contact_form_fields"])
+	require.Equal(t, "https://wa.me/48601234567", row["whatsapp"])
+	require.Equal(t, "https://m.me/pompykowalski", row["messenger"])
+	require.Equal(t, "https://t.me/pompy_kowalski", row["telegram"])
+	require.Equal(t, "Tidio", row["chat_widgets"])
+}
@@ -11,14 +11,21 @@ import (
 	"github.com/playwright-community/playwright-go"
 )
 
+type PlaceJobOptions func(*PlaceJob)
+
 type PlaceJob struct {
 	scrapemate.Job
 
 	UsageInResultststs bool
 	ExtractEmail       bool
+	Relevance          RelevanceConfig
+	// Query is the search the place was found with.
+	Query string
+
+	enrichment *Enrichment
 }
 
-func NewPlaceJob(parentID, langCode, u string, extractEmail bool) *PlaceJob {
+func NewPlaceJob(parentID, langCode, u string, extractEmail bool, opts ...PlaceJobOptions) *PlaceJob {
 	const (
 		defaultPrio       = scrapemate.PriorityMedium
 		defaultMaxRetries = 3
@@ -39,9 +46,35 @@ func NewPlaceJob(parentID, langCode, u string, extractEmail bool) *PlaceJob {
 	job.UsageInResultststs = true
 	job.ExtractEmail = extractEmail
 
+	for _, opt := range opts {
+		opt(&job)
+	}
+
 	return &job
 }
 
+// WithPlaceJobEnrichment sets the services used to enrich the place.
+func WithPlaceJobEnrichment(e *Enrichment) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.enrichment = e
+	}
+}
+
+// WithPlaceJobRelevance sets the terms the website of the place is scored
+// against.
+func WithPlaceJobRelevance(cfg RelevanceConfig) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.Relevance = cfg
+	}
+}
+
+// WithPlaceJobQuery sets the search the place was found with.
+func WithPlaceJobQuery(query string) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.Query = query
+	}
+}
+
 func (j *PlaceJob) Process(_ context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
@@ -60,13 +93,14 @@ func (j *PlaceJob) Process(_ context.Context, resp *scrapemate.Response) (any, [
 	}
 
 	entry.ID = j.ParentID
+	entry.Query = j.Query
 
 	if entry.Link == "" {
 		entry.Link = j.GetURL()
 	}
 
 	if j.ExtractEmail && entry.IsWebsiteValidForEmail() {
-		emailJob := NewEmailJob(j.ID, &entry)
+		emailJob := NewEmailJob(j.ID, &entry, WithEmailJobEnrichment(j.enrichment), WithEmailJobRelevance(j.Relevance))
 
 		j.UsageInResultststs = false
 
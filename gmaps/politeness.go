@@ -0,0 +1,272 @@
+package gmaps
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+)
+
+const (
+	// DefaultUserAgent identifies the crawler to the business websites.
+	DefaultUserAgent = "google-maps-scraper/1.0 (+https://github.com/wojciechkapala/google-maps-scraper)"
+
+	defaultHostConcurrency = 1
+	defaultHostDelay       = 2 * time.Second
+	robotsTimeout          = 10 * time.Second
+	// robotsMaxSize is the limit from RFC 9309.
+	robotsMaxSize = 500 * 1024
+)
+
+// ErrDisallowedByRobots is returned for pages the robots.txt of the website
+// does not let the crawler visit.
+var ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+
+// PolitenessConfig configures the visits of the business websites.
+type PolitenessConfig struct {
+	// UserAgent is sent with every request and matched against robots.txt.
+	UserAgent string
+	// HostConcurrency is the number of pages of a host visited at once.
+	HostConcurrency int
+	// HostDelay is the minimum time between two visits of a host, 2s when
+	// zero and none when negative. A longer Crawl-delay in robots.txt takes
+	// precedence.
+	HostDelay time.Duration
+	// IgnoreRobots lists the domains, including their subdomains, whose
+	// robots.txt is not checked.
+	IgnoreRobots []string
+}
+
+// Politeness keeps the website visits within robots.txt and the per host
+// limits. It is shared by all the jobs of a run. A nil *Politeness allows
+// everything.
+type Politeness struct {
+	cfg    PolitenessConfig
+	client *http.Client
+
+	mu     sync.Mutex
+	robots map[string]*robotsEntry
+	hosts  map[string]*hostState
+}
+
+type robotsEntry struct {
+	mu      sync.Mutex
+	fetched bool
+	data    *robotstxt.RobotsData
+}
+
+type hostState struct {
+	slots chan struct{}
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func NewPoliteness(cfg PolitenessConfig) *Politeness {
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = DefaultUserAgent
+	}
+
+	if cfg.HostConcurrency <= 0 {
+		cfg.HostConcurrency = defaultHostConcurrency
+	}
+
+	if cfg.HostDelay == 0 {
+		cfg.HostDelay = defaultHostDelay
+	}
+
+	for i := range cfg.IgnoreRobots {
+		cfg.IgnoreRobots[i] = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(cfg.IgnoreRobots[i]), "."))
+	}
+
+	return &Politeness{
+		cfg:    cfg,
+		client: &http.Client{Timeout: robotsTimeout},
+		robots: make(map[string]*robotsEntry),
+		hosts:  make(map[string]*hostState),
+	}
+}
+
+// UserAgent returns the user agent of the crawler, empty for a nil
+// *Politeness so the browser default is kept.
+func (p *Politeness) UserAgent() string {
+	if p == nil {
+		return ""
+	}
+
+	return p.cfg.UserAgent
+}
+
+// Allowed reports whether robots.txt lets the crawler visit rawURL. The
+// robots.txt of every host is fetched once per run, or again after the
+// website could not be reached.
+func (p *Politeness) Allowed(ctx context.Context, rawURL string) bool {
+	if p == nil {
+		return true
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return true
+	}
+
+	if p.ignoresRobots(u.Hostname()) {
+		return true
+	}
+
+	data := p.robotsData(ctx, u)
+	if data == nil {
+		return true
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	return data.TestAgent(path, p.cfg.UserAgent)
+}
+
+// Acquire waits for the delay since the previous visit of the host of rawURL
+// and then for a free slot of the host. The delay is waited out before the
+// slot is taken, so that a job waiting for its turn does not keep the slot
+// from the others. The returned function releases the slot.
+func (p *Politeness) Acquire(ctx context.Context, rawURL string) (func(), error) {
+	if p == nil {
+		return func() {}, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return func() {}, nil
+	}
+
+	host := p.host(u.Host)
+
+	delay := p.cfg.HostDelay
+	if data := p.robotsData(ctx, u); data != nil && !p.ignoresRobots(u.Hostname()) {
+		if crawlDelay := data.FindGroup(p.cfg.UserAgent).CrawlDelay; crawlDelay > delay {
+			delay = crawlDelay
+		}
+	}
+
+	// reserve the next visit time, so waiting jobs of the host are spaced
+	host.mu.Lock()
+
+	start := host.next
+	if now := time.Now(); start.Before(now) {
+		start = now
+	}
+
+	host.next = start.Add(delay)
+	host.mu.Unlock()
+
+	if err := sleep(ctx, time.Until(start)); err != nil {
+		return nil, err
+	}
+
+	select {
+	case host.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return func() { <-host.slots }, nil
+}
+
+func (p *Politeness) host(name string) *hostState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	h, ok := p.hosts[name]
+	if !ok {
+		h = &hostState{slots: make(chan struct{}, p.cfg.HostConcurrency)}
+		p.hosts[name] = h
+	}
+
+	return h
+}
+
+func (p *Politeness) ignoresRobots(hostname string) bool {
+	hostname = strings.ToLower(hostname)
+
+	for _, domain := range p.cfg.IgnoreRobots {
+		if domain != "" && (hostname == domain || strings.HasSuffix(hostname, "."+domain)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// robotsData returns the robots.txt of the website of u, nil when it could
+// not be fetched. It is fetched with a context of its own, since the answer
+// is kept for the other jobs of the host, and fetched again by the next job
+// when the website could not be reached.
+func (p *Politeness) robotsData(ctx context.Context, u *url.URL) *robotstxt.RobotsData {
+	key := u.Scheme + "://" + u.Host
+
+	p.mu.Lock()
+
+	entry, ok := p.robots[key]
+	if !ok {
+		entry = &robotsEntry{}
+		p.robots[key] = entry
+	}
+
+	p.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if !entry.fetched {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), robotsTimeout)
+		defer cancel()
+
+		entry.data, entry.fetched = p.fetchRobots(ctx, key+"/robots.txt")
+	}
+
+	return entry.data
+}
+
+// fetchRobots downloads robots.txt and reports whether the answer is final.
+// A missing file allows everything and a server error disallows everything,
+// as in RFC 9309. When the website cannot be reached nil is returned, the
+// page visit fails on its own, and the answer is not final.
+func (p *Politeness) fetchRobots(ctx context.Context, robotsURL string) (*robotstxt.RobotsData, bool) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, http.NoBody)
+	if err != nil {
+		return nil, true
+	}
+
+	req.Header.Set("User-Agent", p.cfg.UserAgent)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, false
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, robotsMaxSize))
+	if err != nil {
+		return nil, false
+	}
+
+	data, err := robotstxt.FromStatusAndBytes(resp.StatusCode, body)
+	if err != nil {
+		return nil, true
+	}
+
+	return data, true
+}
@@ -0,0 +1,136 @@
+package gmaps_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+const robotsTxt = `User-agent: *
+Disallow: /private
+
+User-agent: google-maps-scraper
+Disallow: /kontakt
+Crawl-delay: 1
+`
+
+func newRobotsStub(t *testing.T, status int, fetches *int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/robots.txt" {
+			return
+		}
+
+		atomic.AddInt32(fetches, 1)
+		require.Equal(t, gmaps.DefaultUserAgent, r.UserAgent())
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(robotsTxt))
+	}))
+}
+
+func Test_PolitenessAllowed(t *testing.T) {
+	var fetches int32
+
+	srv := newRobotsStub(t, http.StatusOK, &fetches)
+	defer srv.Close()
+
+	p := gmaps.NewPoliteness(gmaps.PolitenessConfig{})
+	ctx := context.Background()
+
+	require.True(t, p.Allowed(ctx, srv.URL+"/"))
+	// only the most specific group applies to the crawler
+	require.True(t, p.Allowed(ctx, srv.URL+"/private"))
+	require.False(t, p.Allowed(ctx, srv.URL+"/kontakt"))
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// the 127.0.0.1 host of the stub is allow-listed
+	p = gmaps.NewPoliteness(gmaps.PolitenessConfig{IgnoreRobots: []string{"127.0.0.1"}})
+	require.True(t, p.Allowed(ctx, srv.URL+"/kontakt"))
+
+	var nilPoliteness *gmaps.Politeness
+	require.True(t, nilPoliteness.Allowed(ctx, srv.URL+"/kontakt"))
+}
+
+func Test_PolitenessServerError(t *testing.T) {
+	var fetches int32
+
+	srv := newRobotsStub(t, http.StatusServiceUnavailable, &fetches)
+	defer srv.Close()
+
+	p := gmaps.NewPoliteness(gmaps.PolitenessConfig{})
+	require.False(t, p.Allowed(context.Background(), srv.URL+"/"))
+}
+
+func Test_PolitenessAcquire(t *testing.T) {
+	var fetches int32
+
+	srv := newRobotsStub(t, http.StatusNotFound, &fetches)
+	defer srv.Close()
+
+	p := gmaps.NewPoliteness(gmaps.PolitenessConfig{HostDelay: 100 * time.Millisecond})
+	ctx := context.Background()
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		release, err := p.Acquire(ctx, srv.URL+"/")
+		require.NoError(t, err)
+		release()
+	}
+
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	// the host slot is taken until it is released
+	p = gmaps.NewPoliteness(gmaps.PolitenessConfig{HostDelay: -1})
+
+	release, err := p.Acquire(ctx, srv.URL+"/")
+	require.NoError(t, err)
+
+	timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	_, err = p.Acquire(timeout, srv.URL+"/")
+	require.Error(t, err)
+
+	release()
+}
+
+func Test_PolitenessRobotsFetch(t *testing.T) {
+	var fetches int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first fetch fails like an unreachable website
+		if atomic.AddInt32(&fetches, 1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+
+			return
+		}
+
+		_, _ = w.Write([]byte(robotsTxt))
+	}))
+	defer srv.Close()
+
+	p := gmaps.NewPoliteness(gmaps.PolitenessConfig{})
+
+	require.True(t, p.Allowed(context.Background(), srv.URL+"/kontakt"))
+
+	// the failure is not kept, and the context of the job asking first
+	// does not cut the fetch short for the others
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.False(t, p.Allowed(ctx, srv.URL+"/kontakt"))
+	require.False(t, p.Allowed(context.Background(), srv.URL+"/kontakt"))
+	require.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
@@ -0,0 +1,491 @@
+package gmaps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxRetries       = 3
+	defaultBaseDelay        = 500 * time.Millisecond
+	defaultMaxDelay         = 30 * time.Second
+	defaultFailureThreshold = 5
+	defaultCooldown         = time.Minute
+	// maxRetryAfter caps the Retry-After header, a registry asking to
+	// wait longer is treated as unavailable.
+	maxRetryAfter = 2 * time.Minute
+)
+
+// DefaultRateLimits are the requests per second allowed for the registries
+// not listed in the -registry-rate flag.
+var DefaultRateLimits = map[string]float64{
+	SourceCEIDG: 2,
+	SourceGUS:   5,
+	SourceKRS:   2,
+	SourceVIES:  1,
+}
+
+// ErrCircuitOpen is returned without calling a registry that has failed
+// repeatedly, until its cooldown has passed.
+var ErrCircuitOpen = errors.New("registry temporarily unavailable")
+
+// RateLimit configures a RegistryLimiter. Zero values use the defaults.
+type RateLimit struct {
+	// Rate is the number of requests per second, zero means no limit.
+	Rate float64
+	// MaxRetries is the number of retries of a request that failed with
+	// a network error, 429 or a 5xx status code.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry, doubled for every
+	// next retry up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// FailureThreshold is the number of failed requests in a row after
+	// which the registry is not called for Cooldown.
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// RegistryStats counts the requests sent to a registry during a run.
+type RegistryStats struct {
+	Source   string
+	Requests int
+	Retries  int
+	// Failed counts the requests that failed after all the retries.
+	Failed int
+	// Skipped counts the requests not sent because the circuit was open.
+	Skipped int
+}
+
+// RegistryLimiter is shared by all the requests to a single registry. It
+// limits their rate with a token bucket, retries failed requests with an
+// exponential backoff honoring Retry-After, and stops calling the registry
+// for a while after repeated failures.
+type RegistryLimiter struct {
+	source string
+	cfg    RateLimit
+
+	mu           sync.Mutex
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+	failures     int
+	openUntil    time.Time
+	stats        RegistryStats
+}
+
+func NewRegistryLimiter(source string, cfg RateLimit) *RegistryLimiter {
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+
+	if cfg.BaseDelay == 0 {
+		cfg.BaseDelay = defaultBaseDelay
+	}
+
+	if cfg.MaxDelay == 0 {
+		cfg.MaxDelay = defaultMaxDelay
+	}
+
+	if cfg.FailureThreshold == 0 {
+		cfg.FailureThreshold = defaultFailureThreshold
+	}
+
+	if cfg.Cooldown == 0 {
+		cfg.Cooldown = defaultCooldown
+	}
+
+	return &RegistryLimiter{
+		source: source,
+		cfg:    cfg,
+		tokens: burst(cfg.Rate),
+		last:   time.Now(),
+		stats:  RegistryStats{Source: source},
+	}
+}
+
+// burst allows a second worth of requests at once.
+func burst(rate float64) float64 {
+	if rate < 1 {
+		return 1
+	}
+
+	return rate
+}
+
+// Stats returns the counters of the limiter.
+func (l *RegistryLimiter) Stats() RegistryStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stats
+}
+
+// Transport returns a RoundTripper sending the requests through the limiter.
+// The timeout applies to every attempt, not to the whole request.
+func (l *RegistryLimiter) Transport(timeout time.Duration) http.RoundTripper {
+	return &limitedTransport{limiter: l, timeout: timeout, next: http.DefaultTransport}
+}
+
+// registryHTTPClient returns the HTTP client of a registry API client.
+func registryHTTPClient(timeout time.Duration, limiter *RegistryLimiter) *http.Client {
+	if limiter == nil {
+		return &http.Client{Timeout: timeout}
+	}
+
+	return &http.Client{Transport: limiter.Transport(timeout)}
+}
+
+// wait blocks until a token is available and no Retry-After is pending.
+func (l *RegistryLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+
+		now := time.Now()
+
+		var delay time.Duration
+
+		switch {
+		case now.Before(l.blockedUntil):
+			delay = l.blockedUntil.Sub(now)
+		case l.cfg.Rate <= 0:
+			l.mu.Unlock()
+
+			return nil
+		default:
+			l.tokens += now.Sub(l.last).Seconds() * l.cfg.Rate
+			if limit := burst(l.cfg.Rate); l.tokens > limit {
+				l.tokens = limit
+			}
+
+			l.last = now
+
+			if l.tokens >= 1 {
+				l.tokens--
+				l.mu.Unlock()
+
+				return nil
+			}
+
+			delay = time.Duration((1 - l.tokens) / l.cfg.Rate * float64(time.Second))
+		}
+
+		l.mu.Unlock()
+
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// allow reports whether the circuit lets a request through. Once the
+// cooldown has passed requests go through again, and the next failure opens
+// the circuit right away.
+func (l *RegistryLimiter) allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if time.Now().Before(l.openUntil) {
+		l.stats.Skipped++
+
+		return false
+	}
+
+	l.stats.Requests++
+
+	return true
+}
+
+func (l *RegistryLimiter) retried(retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stats.Retries++
+
+	// the registry asked everyone to slow down, not only this request
+	if until := time.Now().Add(retryAfter); retryAfter > 0 && until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+func (l *RegistryLimiter) done(failed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !failed {
+		l.failures = 0
+
+		return
+	}
+
+	l.stats.Failed++
+	l.failures++
+
+	if l.failures >= l.cfg.FailureThreshold {
+		l.openUntil = time.Now().Add(l.cfg.Cooldown)
+	}
+}
+
+func (l *RegistryLimiter) backoff(attempt int) time.Duration {
+	delay := l.cfg.BaseDelay << attempt
+	if delay <= 0 || delay > l.cfg.MaxDelay {
+		delay = l.cfg.MaxDelay
+	}
+
+	return delay
+}
+
+type limitedTransport struct {
+	limiter *RegistryLimiter
+	timeout time.Duration
+	next    http.RoundTripper
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	l := t.limiter
+
+	if !l.allow() {
+		return nil, fmt.Errorf("%s: %w", l.source, ErrCircuitOpen)
+	}
+
+	// a request whose body cannot be read again is sent once
+	maxRetries := l.cfg.MaxRetries
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		maxRetries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := l.wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.attempt(req, attempt)
+
+		retryable := isRetryable(resp, err) && req.Context().Err() == nil
+		if !retryable {
+			l.done(false)
+
+			return resp, err
+		}
+
+		retryAfter := retryAfterDelay(resp)
+
+		if attempt >= maxRetries || retryAfter > maxRetryAfter {
+			l.done(true)
+
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		l.retried(retryAfter)
+
+		delay := l.backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *limitedTransport) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+	}
+
+	r := req.Clone(ctx)
+
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+
+			return nil, err
+		}
+
+		r.Body = body
+	}
+
+	resp, err := t.next.RoundTrip(r)
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	// the attempt timeout covers reading the body too
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+
+	return err
+}
+
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// retryAfterDelay parses the Retry-After header, given either in seconds or
+// as an HTTP date.
+func retryAfterDelay(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+
+	v := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(v); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RegistryLimits holds one RegistryLimiter per registry, so that all the
+// clients of a registry share its limit.
+type RegistryLimits struct {
+	rates map[string]float64
+
+	mu       sync.Mutex
+	limiters map[string]*RegistryLimiter
+}
+
+// NewRegistryLimits creates the limits. Registries missing from rates use
+// DefaultRateLimits.
+func NewRegistryLimits(rates map[string]float64) *RegistryLimits {
+	merged := make(map[string]float64, len(DefaultRateLimits)+len(rates))
+	for source, rate := range DefaultRateLimits {
+		merged[source] = rate
+	}
+
+	for source, rate := range rates {
+		merged[source] = rate
+	}
+
+	return &RegistryLimits{
+		rates:    merged,
+		limiters: make(map[string]*RegistryLimiter),
+	}
+}
+
+// Limiter returns the limiter of the registry. It returns nil for a nil
+// *RegistryLimits, which disables limiting.
+func (r *RegistryLimits) Limiter(source string) *RegistryLimiter {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := r.limiters[source]
+	if !ok {
+		l = NewRegistryLimiter(source, RateLimit{Rate: r.rates[source]})
+		r.limiters[source] = l
+	}
+
+	return l
+}
+
+// Stats returns the counters of the registries that were used, sorted by
+// source.
+func (r *RegistryLimits) Stats() []RegistryStats {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make([]RegistryStats, 0, len(r.limiters))
+	for _, l := range r.limiters {
+		stats = append(stats, l.Stats())
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Source < stats[j].Source
+	})
+
+	return stats
+}
+
+// ParseRateLimits parses rates in the source=requests_per_second form, for
+// example "ceidg=1,gus=0.5".
+func ParseRateLimits(s string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		source, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit %q, expected source=requests_per_second", part)
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("invalid rate limit for %s: %q", source, value)
+		}
+
+		rates[strings.TrimSpace(source)] = rate
+	}
+
+	return rates, nil
+}
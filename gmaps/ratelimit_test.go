@@ -0,0 +1,120 @@
+package gmaps_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+func Test_RegistryLimiterRetry(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		require.Equal(t, "payload", string(body))
+
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	limiter := gmaps.NewRegistryLimiter(gmaps.SourceCEIDG, gmaps.RateLimit{BaseDelay: time.Millisecond})
+	client := &http.Client{Transport: limiter.Transport(time.Second)}
+
+	resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("payload"))
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	require.Equal(t, gmaps.RegistryStats{Source: gmaps.SourceCEIDG, Requests: 1, Retries: 1}, limiter.Stats())
+}
+
+func Test_RegistryLimiterCircuit(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	limiter := gmaps.NewRegistryLimiter(gmaps.SourceKRS, gmaps.RateLimit{
+		MaxRetries:       1,
+		BaseDelay:        time.Millisecond,
+		FailureThreshold: 2,
+		Cooldown:         time.Hour,
+	})
+	client := &http.Client{Transport: limiter.Transport(time.Second)}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	_, err := client.Get(srv.URL)
+	require.True(t, errors.Is(err, gmaps.ErrCircuitOpen))
+
+	require.Equal(t, int32(4), atomic.LoadInt32(&calls))
+	require.Equal(t, gmaps.RegistryStats{
+		Source:   gmaps.SourceKRS,
+		Requests: 2,
+		Retries:  2,
+		Failed:   2,
+		Skipped:  1,
+	}, limiter.Stats())
+}
+
+func Test_RegistryLimiterRate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	limits := gmaps.NewRegistryLimits(map[string]float64{gmaps.SourceVIES: 10})
+	client := &http.Client{Transport: limits.Limiter(gmaps.SourceVIES).Transport(time.Second)}
+
+	start := time.Now()
+
+	// the first 10 requests use the burst, the next 3 wait for tokens
+	for i := 0; i < 13; i++ {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, http.NoBody)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	require.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond)
+	require.Equal(t, []gmaps.RegistryStats{{Source: gmaps.SourceVIES, Requests: 13}}, limits.Stats())
+}
+
+func Test_ParseRateLimits(t *testing.T) {
+	rates, err := gmaps.ParseRateLimits("ceidg=1, gus=0.5")
+	require.NoError(t, err)
+	require.Equal(t, map[string]float64{gmaps.SourceCEIDG: 1, gmaps.SourceGUS: 0.5}, rates)
+
+	_, err = gmaps.ParseRateLimits("ceidg")
+	require.Error(t, err)
+
+	_, err = gmaps.ParseRateLimits("ceidg=-1")
+	require.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package gmaps
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gosom/kit/logging"
+)
+
+const redacted = "[REDACTED]"
+
+var _ logging.Logger = (*redactingLogger)(nil)
+
+// redactingLogger replaces every occurrence of a secret in the message and
+// in the string and error arguments before passing them to the next logger.
+type redactingLogger struct {
+	next    logging.Logger
+	secrets []string
+}
+
+// NewRedactingLogger wraps next so that the given secrets never reach the
+// log output. Empty secrets are ignored.
+func NewRedactingLogger(next logging.Logger, secrets ...string) logging.Logger {
+	l := redactingLogger{next: next}
+
+	for _, s := range secrets {
+		if s != "" {
+			l.secrets = append(l.secrets, s)
+		}
+	}
+
+	return &l
+}
+
+func (l *redactingLogger) Info(msg string, args ...any) {
+	l.next.Info(l.redact(msg), l.redactArgs(args)...)
+}
+
+func (l *redactingLogger) Warn(msg string, args ...any) {
+	l.next.Warn(l.redact(msg), l.redactArgs(args)...)
+}
+
+func (l *redactingLogger) Error(msg string, args ...any) {
+	l.next.Error(l.redact(msg), l.redactArgs(args)...)
+}
+
+func (l *redactingLogger) Debug(msg string, args ...any) {
+	l.next.Debug(l.redact(msg), l.redactArgs(args)...)
+}
+
+func (l *redactingLogger) Trace(msg string, args ...any) {
+	l.next.Trace(l.redact(msg), l.redactArgs(args)...)
+}
+
+func (l *redactingLogger) Fatal(msg string, args ...any) {
+	l.next.Fatal(l.redact(msg), l.redactArgs(args)...)
+}
+
+func (l *redactingLogger) Panic(msg string, args ...any) {
+	l.next.Panic(l.redact(msg), l.redactArgs(args)...)
+}
+
+func (l *redactingLogger) Log(level logging.Level, msg string, args ...any) {
+	l.next.Log(level, l.redact(msg), l.redactArgs(args)...)
+}
+
+func (l *redactingLogger) With(args ...any) logging.Logger {
+	return &redactingLogger{next: l.next.With(l.redactArgs(args)...), secrets: l.secrets}
+}
+
+func (l *redactingLogger) Level(level logging.Level) logging.Logger {
+	return &redactingLogger{next: l.next.Level(level), secrets: l.secrets}
+}
+
+func (l *redactingLogger) NewContext(ctx context.Context) context.Context {
+	return l.next.NewContext(ctx)
+}
+
+func (l *redactingLogger) redact(s string) string {
+	for _, secret := range l.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+
+	return s
+}
+
+func (l *redactingLogger) redactArgs(args []any) []any {
+	out := make([]any, len(args))
+
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			out[i] = l.redact(v)
+		case []byte:
+			out[i] = l.redact(string(v))
+		case error:
+			out[i] = redactError(v, l.secrets...)
+		default:
+			out[i] = arg
+		}
+	}
+
+	return out
+}
+
+// redactError returns err unchanged unless its message contains a secret.
+func redactError(err error, secrets ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+
+	for _, secret := range secrets {
+		if secret != "" {
+			msg = strings.ReplaceAll(msg, secret, redacted)
+		}
+	}
+
+	if msg == err.Error() {
+		return err
+	}
+
+	return errors.New(msg)
+}
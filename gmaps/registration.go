@@ -0,0 +1,95 @@
+package gmaps
+
+import "strings"
+
+// Sources of the business data, used to attribute registrations and
+// identifiers.
+const (
+	SourceWebsite = "website"
+	SourceCEIDG   = "ceidg"
+	SourceKRS     = "krs"
+	SourceGUS     = "gus"
+)
+
+// CompanyRegistration is the registry record of the business behind an entry.
+type CompanyRegistration struct {
+	// Source is the registry the record comes from, e.g. SourceCEIDG.
+	Source         string              `json:"source"`
+	ID             string              `json:"id"`
+	Name           string              `json:"name"`
+	LegalForm      string              `json:"legal_form,omitempty"`
+	OwnerFirstName string              `json:"owner_first_name"`
+	OwnerLastName  string              `json:"owner_last_name"`
+	NIP            string              `json:"nip"`
+	REGON          string              `json:"regon"`
+	KRS            string              `json:"krs,omitempty"`
+	Address        RegistrationAddress `json:"address"`
+	StartDate      string              `json:"start_date"`
+	Status         string              `json:"status"`
+	Link           string              `json:"link"`
+	// MatchScore tells how well the record matches the Google Maps place,
+	// see MatchScore. LowConfidence is set when it is below MatchThreshold.
+	MatchScore    float64 `json:"match_score"`
+	LowConfidence bool    `json:"low_confidence"`
+	// MatchedOn are the parts of the place the score compared, "name",
+	// "street" and "city". A score of the name alone is the weakest.
+	MatchedOn []string `json:"matched_on,omitempty"`
+}
+
+type RegistrationAddress struct {
+	Street      string `json:"street"`
+	Building    string `json:"building"`
+	PostalCode  string `json:"postal_code"`
+	City        string `json:"city"`
+	Commune     string `json:"commune"`
+	County      string `json:"county"`
+	Voivodeship string `json:"voivodeship"`
+	Country     string `json:"country"`
+}
+
+// Owner returns the full name of the owner.
+func (r *CompanyRegistration) Owner() string {
+	return strings.TrimSpace(r.OwnerFirstName + " " + r.OwnerLastName)
+}
+
+// String returns the address in the usual Polish form,
+// e.g. "Długa 5, 00-001 Warszawa".
+func (a RegistrationAddress) String() string {
+	street := strings.TrimSpace(a.Street + " " + a.Building)
+	city := strings.TrimSpace(a.PostalCode + " " + a.City)
+
+	switch {
+	case street == "":
+		return city
+	case city == "":
+		return street
+	default:
+		return street + ", " + city
+	}
+}
+
+// Registration converts the firm to a CompanyRegistration.
+func (f *CEIDGFirm) Registration() *CompanyRegistration {
+	return &CompanyRegistration{
+		Source:         SourceCEIDG,
+		ID:             f.ID,
+		Name:           f.Nazwa,
+		OwnerFirstName: f.Wlasciciel.Imie,
+		OwnerLastName:  f.Wlasciciel.Nazwisko,
+		NIP:            f.Wlasciciel.Nip,
+		REGON:          f.Wlasciciel.Regon,
+		Address: RegistrationAddress{
+			Street:      f.AdresDzialalnosci.Ulica,
+			Building:    f.AdresDzialalnosci.Budynek,
+			PostalCode:  f.AdresDzialalnosci.Kod,
+			City:        f.AdresDzialalnosci.Miasto,
+			Commune:     f.AdresDzialalnosci.Gmina,
+			County:      f.AdresDzialalnosci.Powiat,
+			Voivodeship: f.AdresDzialalnosci.Wojewodztwo,
+			Country:     f.AdresDzialalnosci.Kraj,
+		},
+		StartDate: f.DataRozpoczecia,
+		Status:    f.Status,
+		Link:      f.Link,
+	}
+}
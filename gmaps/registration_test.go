@@ -0,0 +1,46 @@
+package gmaps_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+func Test_EntryWithRegistration(t *testing.T) {
+	firm := gmaps.CEIDGFirm{
+		Nazwa: `Usługi "Pompa" Jan Kowalski`,
+		Wlasciciel: gmaps.CEIDGOwner{
+			Imie:     "Jan",
+			Nazwisko: "Kowalski",
+			Nip:      "5260250274",
+		},
+		AdresDzialalnosci: gmaps.CEIDGAddress{
+			Ulica:   "Długa",
+			Budynek: "5",
+			Kod:     "00-001",
+			Miasto:  "Warszawa",
+		},
+		Status: "AKTYWNY",
+	}
+
+	entry := gmaps.Entry{Title: "Pompa", CEIDG: firm.Registration()}
+
+	row := entry.CsvRow()
+	require.Len(t, row, len(entry.CsvHeaders()))
+	require.Contains(t, row, `Usługi "Pompa" Jan Kowalski`)
+	require.Contains(t, row, "Jan Kowalski")
+	require.Contains(t, row, "Długa 5, 00-001 Warszawa")
+
+	raw, err := json.Marshal(&entry)
+	require.NoError(t, err)
+
+	var decoded gmaps.Entry
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	require.Equal(t, entry.CEIDG, decoded.CEIDG)
+
+	empty := gmaps.Entry{}
+	require.Len(t, empty.CsvRow(), len(empty.CsvHeaders()))
+}
@@ -0,0 +1,193 @@
+package gmaps_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+const krsOdpis = `{"odpis":{"naglowekA":{"numerKRS":"0000123456","dataRejestracjiWKRS":"01.02.2010"},
+"dane":{"dzial1":{"danePodmiotu":{"formaPrawna":"SPÓŁKA Z OGRANICZONĄ ODPOWIEDZIALNOŚCIĄ",
+"identyfikatory":{"regon":"12345678500000","nip":"5260250274"},"nazwa":"POMPY KOWALSKI SP. Z O.O."},
+"siedzibaIAdres":{"adres":{"ulica":"UL. DŁUGA","nrDomu":"5","miejscowosc":"WARSZAWA","kodPocztowy":"00-001"}}}}}}`
+
+func newKRSStub(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/krs/OdpisAktualny/0000123456" || r.URL.Query().Get("rejestr") != "P" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(krsOdpis))
+	}))
+}
+
+// newBIRStub answers like the BIR service, including the MTOM wrapping.
+func newBIRStub(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var result string
+
+		switch {
+		case strings.Contains(string(body), "<ns:Zaloguj>"):
+			require.Contains(t, string(body), "bir-key")
+
+			result = `<ZalogujResult>session-1</ZalogujResult>`
+		case strings.Contains(string(body), "<dat:Nip>5260250274</dat:Nip>"):
+			require.Equal(t, "session-1", r.Header.Get("sid"))
+
+			result = `<DaneSzukajPodmiotyResult>&lt;root&gt;&lt;dane&gt;&lt;Regon&gt;123456785&lt;/Regon&gt;` +
+				`&lt;Nip&gt;5260250274&lt;/Nip&gt;&lt;Nazwa&gt;POMPY KOWALSKI SP. Z O.O.&lt;/Nazwa&gt;` +
+				`&lt;Miejscowosc&gt;Warszawa&lt;/Miejscowosc&gt;&lt;/dane&gt;&lt;/root&gt;</DaneSzukajPodmiotyResult>`
+		default:
+			result = `<DaneSzukajPodmiotyResult>&lt;root&gt;&lt;dane&gt;&lt;ErrorCode&gt;4&lt;/ErrorCode&gt;` +
+				`&lt;/dane&gt;&lt;/root&gt;</DaneSzukajPodmiotyResult>`
+		}
+
+		w.Header().Set("Content-Type", `multipart/related; type="application/xop+xml"`)
+		_, _ = w.Write([]byte("--uuid:1\r\nContent-Type: application/xop+xml\r\n\r\n" +
+			`<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"><s:Body>` + result +
+			"</s:Body></s:Envelope>\r\n--uuid:1--"))
+	}))
+}
+
+func Test_KRSAPIClient(t *testing.T) {
+	srv := newKRSStub(t)
+	defer srv.Close()
+
+	client := gmaps.NewKRSAPIClient(gmaps.KRSConfig{URL: srv.URL})
+
+	record, err := client.LookupKRS(context.Background(), "0000123456")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+
+	reg := record.Registration()
+	require.Equal(t, gmaps.SourceKRS, reg.Source)
+	require.Equal(t, "POMPY KOWALSKI SP. Z O.O.", reg.Name)
+	require.Equal(t, "123456785", reg.REGON)
+
+	record, err = client.LookupKRS(context.Background(), "0000999999")
+	require.NoError(t, err)
+	require.Nil(t, record)
+}
+
+func Test_BIRSOAPClient(t *testing.T) {
+	srv := newBIRStub(t)
+	defer srv.Close()
+
+	client, err := gmaps.NewBIRSOAPClient(gmaps.BIRConfig{URL: srv.URL, APIKey: "bir-key"})
+	require.NoError(t, err)
+
+	records, err := client.SearchNIP(context.Background(), "526-025-02-74")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, "123456785", records[0].Regon)
+
+	records, err = client.SearchREGON(context.Background(), "630254537")
+	require.NoError(t, err)
+	require.Empty(t, records)
+}
+
+func Test_BIRSOAPClientSessionDropped(t *testing.T) {
+	logins := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		// the values are escaped in the envelope
+		require.Contains(t, string(body), "?wsdl=1&amp;v=2</wsa:To>")
+
+		var result string
+
+		switch {
+		case strings.Contains(string(body), "<ns:Zaloguj>"):
+			logins++
+			result = fmt.Sprintf(`<ZalogujResult>session-%d</ZalogujResult>`, logins)
+		case r.Header.Get("sid") == "session-1":
+			// GUS dropped the session before it expired
+			result = `<DaneSzukajPodmiotyResult></DaneSzukajPodmiotyResult>`
+		default:
+			result = `<DaneSzukajPodmiotyResult>&lt;root&gt;&lt;dane&gt;&lt;Regon&gt;123456785&lt;/Regon&gt;` +
+				`&lt;/dane&gt;&lt;/root&gt;</DaneSzukajPodmiotyResult>`
+		}
+
+		_, _ = w.Write([]byte(`<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"><s:Body>` + result + "</s:Body></s:Envelope>"))
+	}))
+	defer srv.Close()
+
+	client, err := gmaps.NewBIRSOAPClient(gmaps.BIRConfig{URL: srv.URL + "/?wsdl=1&v=2", APIKey: "bir-key"})
+	require.NoError(t, err)
+
+	records, err := client.SearchNIP(context.Background(), "5260250274")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, 2, logins)
+}
+
+func Test_RegistryChain(t *testing.T) {
+	krsSrv := newKRSStub(t)
+	defer krsSrv.Close()
+
+	birSrv := newBIRStub(t)
+	defer birSrv.Close()
+
+	bir, err := gmaps.NewBIRSOAPClient(gmaps.BIRConfig{URL: birSrv.URL, APIKey: "bir-key"})
+	require.NoError(t, err)
+
+	enrichers := []gmaps.Enricher{
+		gmaps.NewGUSEnricher(bir),
+		gmaps.NewCEIDGEnricher(gmaps.NewInMemoryRegistry(nil)),
+		gmaps.NewKRSEnricher(gmaps.NewKRSAPIClient(gmaps.KRSConfig{URL: krsSrv.URL})),
+	}
+
+	entry := &gmaps.Entry{
+		Title: "Pompy Kowalski",
+		City:  "Warszawa",
+		NIP:   "5260250274",
+		KRS:   "0000123456",
+		NIPCandidates: []gmaps.IDCandidate{
+			{Value: "5260250274", Confidence: 0.8, Source: gmaps.SourceWebsite},
+		},
+	}
+
+	_, _, err = gmaps.NewRegistryJob("parent", entry, enrichers).Process(context.Background(), &scrapemate.Response{})
+	require.NoError(t, err)
+
+	require.Nil(t, entry.CEIDG)
+	require.NotNil(t, entry.GUS)
+	require.Equal(t, gmaps.SourceGUS, entry.GUS.Source)
+	require.NotNil(t, entry.KRSRegistry)
+	require.Equal(t, gmaps.SourceKRS, entry.KRSRegistry.Source)
+
+	// the place has no street, the records are scored on the name and city
+	require.Equal(t, []string{"name", "city"}, entry.GUS.MatchedOn)
+
+	row := make(map[string]string)
+	for i, header := range entry.CsvHeaders() {
+		row[header] = entry.CsvRow()[i]
+	}
+
+	require.Equal(t, "gus: 1.00 (name, city); krs: 1.00 (name, city)", row["registry_match"])
+
+	// the REGON is only known from the registries
+	require.Equal(t, "123456785", entry.REGON)
+	require.Equal(t, gmaps.SourceGUS, entry.REGONCandidates[0].Source)
+	require.Equal(t, gmaps.SourceGUS, entry.NIPCandidates[0].Source)
+}
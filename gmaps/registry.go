@@ -0,0 +1,121 @@
+package gmaps
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+)
+
+// RegistryClient looks up companies in the CEIDG business registry.
+type RegistryClient interface {
+	// LookupNIP returns the firms registered under nip. An empty result
+	// and a nil error mean that the registry does not know the NIP.
+	LookupNIP(ctx context.Context, nip string) ([]CEIDGFirm, error)
+}
+
+// CEIDGFirm is a single firm as returned by the registry.
+type CEIDGFirm struct {
+	ID                string       `json:"id"`
+	Nazwa             string       `json:"nazwa"`
+	AdresDzialalnosci CEIDGAddress `json:"adresDzialalnosci"`
+	Wlasciciel        CEIDGOwner   `json:"wlasciciel"`
+	DataRozpoczecia   string       `json:"dataRozpoczecia"`
+	Status            string       `json:"status"`
+	Link              string       `json:"link"`
+}
+
+type CEIDGAddress struct {
+	Ulica       string `json:"ulica"`
+	Budynek     string `json:"budynek"`
+	Miasto      string `json:"miasto"`
+	Wojewodztwo string `json:"wojewodztwo"`
+	Powiat      string `json:"powiat"`
+	Gmina       string `json:"gmina"`
+	Kraj        string `json:"kraj"`
+	Kod         string `json:"kod"`
+}
+
+type CEIDGOwner struct {
+	Imie     string `json:"imie"`
+	Nazwisko string `json:"nazwisko"`
+	Nip      string `json:"nip"`
+	Regon    string `json:"regon"`
+}
+
+var _ RegistryClient = (*InMemoryRegistry)(nil)
+
+// InMemoryRegistry is a RegistryClient backed by a map keyed by NIP.
+// It is meant to be used in tests instead of a real registry.
+type InMemoryRegistry struct {
+	mu      sync.Mutex
+	firms   map[string][]CEIDGFirm
+	lookups []string
+}
+
+func NewInMemoryRegistry(firms map[string][]CEIDGFirm) *InMemoryRegistry {
+	if firms == nil {
+		firms = make(map[string][]CEIDGFirm)
+	}
+
+	return &InMemoryRegistry{firms: firms}
+}
+
+func (r *InMemoryRegistry) LookupNIP(_ context.Context, nip string) ([]CEIDGFirm, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lookups = append(r.lookups, nip)
+
+	return r.firms[cleanNIP(nip)], nil
+}
+
+// Lookups returns the NIP numbers looked up so far, in order.
+func (r *InMemoryRegistry) Lookups() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.lookups...)
+}
+
+// NewCachedRegistry keeps the answers of next, including the NIPs the
+// registry does not know, in the disk cache. Errors are not cached.
+func NewCachedRegistry(next RegistryClient, cache *DiskCache) RegistryClient {
+	return &cachedRegistry{next: next, cache: cache}
+}
+
+type cachedRegistry struct {
+	next  RegistryClient
+	cache *DiskCache
+}
+
+func (r *cachedRegistry) LookupNIP(ctx context.Context, nip string) ([]CEIDGFirm, error) {
+	key := cleanNIP(nip)
+	log := scrapemate.GetLoggerFromContext(ctx)
+
+	var firms []CEIDGFirm
+
+	ok, err := r.cache.Get(SourceCEIDG, key, &firms)
+	if err != nil {
+		log.Error("cache read failed", "source", SourceCEIDG, "error", err)
+	}
+
+	if ok {
+		return firms, nil
+	}
+
+	firms, err = r.next.LookupNIP(ctx, nip)
+	if err != nil {
+		return nil, err
+	}
+
+	if firms == nil {
+		firms = []CEIDGFirm{}
+	}
+
+	if err := r.cache.Set(SourceCEIDG, key, firms); err != nil {
+		log.Error("cache write failed", "source", SourceCEIDG, "error", err)
+	}
+
+	return firms, nil
+}
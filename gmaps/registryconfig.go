@@ -0,0 +1,49 @@
+package gmaps
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// readSettings returns the values of keys read from a file in the .env
+// format. Keys missing from the file, or all of them when path is empty,
+// are taken from the environment. The process environment is not modified.
+func readSettings(path string, keys ...string) (map[string]string, error) {
+	values := make(map[string]string, len(keys))
+
+	if path != "" {
+		var err error
+
+		values, err = godotenv.Read(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read registry config %s: %w", path, err)
+		}
+	}
+
+	for _, key := range keys {
+		if values[key] == "" {
+			values[key] = os.Getenv(key)
+		}
+	}
+
+	return values, nil
+}
+
+// durationSetting parses the duration stored under key, returning def when
+// the key is not set.
+func durationSetting(values map[string]string, key string, def time.Duration) (time.Duration, error) {
+	v := values[key]
+	if v == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return d, nil
+}
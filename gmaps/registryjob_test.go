@@ -0,0 +1,57 @@
+package gmaps_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+func Test_RegistryExtractJob(t *testing.T) {
+	registry := gmaps.NewInMemoryRegistry(map[string][]gmaps.CEIDGFirm{
+		"5260250274": {{ID: "1", Nazwa: "Pompy Kowalski"}},
+	})
+
+	t.Run("attaches the registered firm", func(t *testing.T) {
+		entry := &gmaps.Entry{Title: "Pompy Kowalski", NIP: "5260250274"}
+		job := gmaps.NewRegistryJob("parent", entry, []gmaps.Enricher{gmaps.NewCEIDGEnricher(registry)})
+
+		result, next, err := job.Process(context.Background(), &scrapemate.Response{})
+		require.NoError(t, err)
+		require.Empty(t, next)
+		require.Same(t, entry, result)
+		require.NotNil(t, entry.CEIDG)
+		require.Equal(t, "Pompy Kowalski", entry.CEIDG.Name)
+	})
+
+	t.Run("keeps the entry when the NIP is unknown", func(t *testing.T) {
+		entry := &gmaps.Entry{NIP: "1234563218"}
+		job := gmaps.NewRegistryJob("parent", entry, []gmaps.Enricher{gmaps.NewCEIDGEnricher(registry)})
+
+		result, _, err := job.Process(context.Background(), &scrapemate.Response{})
+		require.NoError(t, err)
+		require.Same(t, entry, result)
+		require.Nil(t, entry.CEIDG)
+	})
+
+	t.Run("picks the best matching firm", func(t *testing.T) {
+		multi := gmaps.NewInMemoryRegistry(map[string][]gmaps.CEIDGFirm{
+			"5213017228": {
+				{ID: "1", Nazwa: "Agencja Reklamowa WebStudio"},
+				{ID: "2", Nazwa: "Kowalski Pompy Ciepła"},
+			},
+		})
+
+		entry := &gmaps.Entry{Title: "Pompy Kowalski", NIP: "5213017228"}
+
+		_, _, err := gmaps.NewRegistryJob("parent", entry, []gmaps.Enricher{gmaps.NewCEIDGEnricher(multi)}).Process(context.Background(), &scrapemate.Response{})
+		require.NoError(t, err)
+		require.Equal(t, "2", entry.CEIDG.ID)
+		require.False(t, entry.CEIDG.LowConfidence)
+	})
+
+	require.Equal(t, []string{"5260250274", "1234563218"}, registry.Lookups())
+}
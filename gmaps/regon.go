@@ -0,0 +1,133 @@
+package gmaps
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	regonRe = regexp.MustCompile(`\b(\d{3}[- ]?\d{3}[- ]?\d{3}(?:\d{5})?)\b`)
+	krsRe   = regexp.MustCompile(`\b(\d{10})\b`)
+
+	regon9Weights  = []int{8, 9, 2, 3, 4, 5, 6, 7}
+	regon14Weights = []int{2, 4, 8, 5, 0, 9, 7, 3, 6, 1, 2, 4, 8}
+)
+
+const (
+	regonShortLen = 9
+	regonLongLen  = 14
+
+	// confidenceLongForm is added to 14 digit REGON numbers, which are
+	// unlikely to be confused with phone numbers.
+	confidenceLongForm = 0.2
+	// confidencePadded is added to KRS numbers written with the usual
+	// leading zeros.
+	confidencePadded = 0.2
+)
+
+// ValidREGON reports whether regon is a 9 or 14 digit REGON with a correct
+// control digit. For the 14 digit form the 9 digit prefix must be valid too.
+func ValidREGON(regon string) bool {
+	regon = cleanNIP(regon)
+
+	if !isDigits(regon) {
+		return false
+	}
+
+	switch len(regon) {
+	case regonShortLen:
+		return regonChecksum(regon, regon9Weights)
+	case regonLongLen:
+		return regonChecksum(regon[:regonShortLen], regon9Weights) && regonChecksum(regon, regon14Weights)
+	default:
+		return false
+	}
+}
+
+func regonChecksum(regon string, weights []int) bool {
+	sum := 0
+	for i, w := range weights {
+		sum += int(regon[i]-'0') * w
+	}
+
+	const mod = 11
+
+	control := sum % mod
+	if control == 10 {
+		control = 0
+	}
+
+	return control == int(regon[len(weights)]-'0')
+}
+
+// ExtractREGONs returns all the checksum valid REGON numbers found in body,
+// best candidates first.
+//
+// 9 digit numbers look like Polish phone numbers, so they are only accepted
+// when preceded by a REGON label. 14 digit numbers are accepted without one.
+func ExtractREGONs(body []byte) []IDCandidate {
+	var candidates []IDCandidate
+
+	for _, m := range regonRe.FindAllSubmatchIndex(body, -1) {
+		start, end := m[0], m[1]
+		if partOfLongerNumber(body, start, end) {
+			continue
+		}
+
+		regon := cleanNIP(string(body[m[2]:m[3]]))
+		if !ValidREGON(regon) {
+			continue
+		}
+
+		labelled := precedingLabel(body, start) == "regon"
+		if !labelled && len(regon) == regonShortLen {
+			continue
+		}
+
+		confidence := confidenceUnseparated
+		if labelled {
+			confidence += confidenceLabel
+		}
+
+		if len(regon) == regonLongLen {
+			confidence += confidenceLongForm
+		}
+
+		candidates = addCandidate(candidates, regon, confidence, SourceWebsite)
+	}
+
+	sortCandidates(candidates)
+
+	return candidates
+}
+
+// ExtractKRSs returns the KRS numbers found in body, best candidates first.
+// KRS numbers have no control digit, so only 10 digit numbers preceded by
+// a KRS label are accepted.
+func ExtractKRSs(body []byte) []IDCandidate {
+	var candidates []IDCandidate
+
+	for _, m := range krsRe.FindAllSubmatchIndex(body, -1) {
+		start, end := m[0], m[1]
+		if partOfLongerNumber(body, start, end) {
+			continue
+		}
+
+		if precedingLabel(body, start) != "krs" {
+			continue
+		}
+
+		krs := string(body[m[2]:m[3]])
+
+		confidence := confidenceUnseparated + confidenceLabel
+		if strings.HasPrefix(krs, "0000") {
+			confidence += confidencePadded
+		}
+
+		candidates = addCandidate(candidates, krs, confidence, SourceWebsite)
+	}
+
+	sortCandidates(candidates)
+
+	return candidates
+}
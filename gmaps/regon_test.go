@@ -0,0 +1,37 @@
+package gmaps_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+func Test_ValidREGON(t *testing.T) {
+	require.True(t, gmaps.ValidREGON("123456785"))
+	require.True(t, gmaps.ValidREGON("12345678500010"))
+	require.False(t, gmaps.ValidREGON("123456789"))
+	require.False(t, gmaps.ValidREGON("12345678500011"))
+	require.False(t, gmaps.ValidREGON("12345678"))
+}
+
+func Test_ExtractREGONsAndKRSs(t *testing.T) {
+	body := []byte(`<footer>
+		NIP: 526-025-02-74, REGON: 123456785, KRS: 0000123456
+		Tel. 630254537, filia 63025453700010
+	</footer>`)
+
+	regons := gmaps.ExtractREGONs(body)
+	require.Len(t, regons, 2)
+	require.Equal(t, "123456785", regons[0].Value)
+	require.Equal(t, "63025453700010", regons[1].Value)
+
+	krs := gmaps.ExtractKRSs(body)
+	require.Len(t, krs, 1)
+	require.Equal(t, "0000123456", krs[0].Value)
+
+	nips := gmaps.ExtractNIPs(body)
+	require.Len(t, nips, 1)
+	require.Equal(t, "5260250274", nips[0].Value)
+}
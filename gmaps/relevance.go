@@ -0,0 +1,241 @@
+package gmaps
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	relevanceWeightTitle       = 0.35
+	relevanceWeightHeadings    = 0.25
+	relevanceWeightDescription = 0.2
+	relevanceWeightBody        = 0.2
+	// relevanceBodyHits is the number of occurrences of a term in the page
+	// text that gives it the full body weight.
+	relevanceBodyHits = 5
+	// languageMinHits is the number of stop words of a language needed to
+	// detect it from the page text.
+	languageMinHits = 3
+)
+
+// relevanceStopWords are left out of the terms taken from a query.
+var relevanceStopWords = map[string]bool{
+	"i": true, "w": true, "we": true, "z": true, "na": true, "do": true, "dla": true, "oraz": true,
+	"the": true, "and": true, "in": true, "of": true, "for": true,
+}
+
+// languageStopWords are frequent words telling the language of a page
+// which does not declare it.
+var languageStopWords = map[string][]string{
+	"pl": {"i", "w", "z", "na", "się", "nie", "do", "jest", "oraz", "dla", "że", "od", "po", "przez", "jak"},
+	"en": {"the", "and", "of", "to", "in", "is", "for", "with", "our", "you", "are", "on", "we"},
+	"de": {"und", "der", "die", "das", "ist", "mit", "für", "nicht", "sie", "wir", "auf", "zu", "ein"},
+	"cs": {"a", "je", "se", "na", "pro", "ve", "že", "jsme", "nebo", "jako", "od", "při"},
+	"uk": {"і", "в", "на", "з", "що", "не", "до", "та", "для", "це", "ми"},
+	"ru": {"и", "в", "на", "с", "что", "не", "для", "это", "мы", "как", "по"},
+	"fr": {"le", "la", "les", "et", "des", "est", "pour", "avec", "nous", "vous", "une", "dans"},
+	"es": {"el", "la", "los", "las", "y", "de", "es", "para", "con", "nuestro", "una", "por"},
+}
+
+// RelevanceConfig configures the scoring of the websites against the query
+// of a job.
+type RelevanceConfig struct {
+	// Terms are the words or phrases a relevant website is about, for
+	// example "pompy ciepła". A phrase matches where all its words are
+	// found. Inflected forms and typos are matched as well.
+	Terms []string
+	// MinScore leaves out the places whose website scores below it. Places
+	// without a website are kept.
+	MinScore float64
+}
+
+// Relevance is the language of a website and how well it matches the terms
+// of the job.
+type Relevance struct {
+	Language string `json:"language"`
+	// Score is in the range [0, 1]: the terms weighted by where they were
+	// found, in the title, the headings, the meta description or the text.
+	Score   float64  `json:"score"`
+	Terms   []string `json:"terms"`
+	Matched []string `json:"matched_terms"`
+}
+
+// RelevanceTerms returns the words of a query which are worth scoring.
+func RelevanceTerms(query string) []string {
+	var terms []string
+
+	for _, t := range strings.Fields(strings.ToLower(query)) {
+		if !relevanceStopWords[normalizeForMatch(t)] {
+			terms = appendUnique(terms, t)
+		}
+	}
+
+	return terms
+}
+
+// QueryPhrase returns the query without the city it ends with, for example
+// "pompy ciepła" for "pompy ciepła Bytom" when Bytom is one of the cities.
+// The cities are compared ignoring case and diacritics.
+func QueryPhrase(query string, cities []string) string {
+	known := make(map[string]bool, len(cities))
+	for _, city := range cities {
+		if city = normalizeForMatch(city); city != "" {
+			known[city] = true
+		}
+	}
+
+	words := strings.Fields(query)
+
+	// the query itself is kept when it is only a city
+	for i := 1; i < len(words); i++ {
+		if known[normalizeForMatch(strings.Join(words[i:], " "))] {
+			return strings.Join(words[:i], " ")
+		}
+	}
+
+	return query
+}
+
+// ScoreRelevance detects the language of the page and scores it against the
+// terms.
+func ScoreRelevance(doc *goquery.Document, terms []string) *Relevance {
+	content := doc.Find("body").Clone()
+	content.Find("script, style, noscript").Remove()
+
+	text := content.Text()
+
+	rel := &Relevance{
+		Language: DetectLanguage(doc, text),
+		Terms:    terms,
+	}
+
+	if len(terms) == 0 {
+		return rel
+	}
+
+	description := doc.Find(`meta[name="description" i]`).First().AttrOr("content", "") + " " +
+		doc.Find(`meta[property="og:description"]`).First().AttrOr("content", "")
+
+	fields := []struct {
+		tokens map[string]int
+		weight float64
+	}{
+		{countTokens(doc.Find("title").First().Text()), relevanceWeightTitle},
+		{countTokens(doc.Find("h1, h2, h3").Text()), relevanceWeightHeadings},
+		{countTokens(description), relevanceWeightDescription},
+	}
+
+	bodyTokens := countTokens(text)
+
+	var score float64
+
+	for _, term := range terms {
+		words := strings.Fields(normalizeForMatch(term))
+		if len(words) == 0 {
+			continue
+		}
+
+		var termScore float64
+
+		for _, f := range fields {
+			if termHits(f.tokens, words) > 0 {
+				termScore += f.weight
+			}
+		}
+
+		if hits := termHits(bodyTokens, words); hits > 0 {
+			termScore += relevanceWeightBody * float64(minInt(hits, relevanceBodyHits)) / relevanceBodyHits
+		}
+
+		if termScore > 0 {
+			rel.Matched = append(rel.Matched, term)
+		}
+
+		score += termScore
+	}
+
+	rel.Score = score / float64(len(terms))
+
+	return rel
+}
+
+func countTokens(s string) map[string]int {
+	tokens := make(map[string]int)
+
+	for _, t := range strings.Fields(normalizeForMatch(s)) {
+		tokens[t]++
+	}
+
+	return tokens
+}
+
+// termHits counts the occurrences of a term made of words, that is of its
+// least frequent word. A word is matched by the tokens equal or similar to
+// it, so that "pompy" matches "pompa" and "ciepła" matches "ciepło".
+func termHits(tokens map[string]int, words []string) int {
+	least := -1
+
+	for _, word := range words {
+		hits := 0
+
+		for t, n := range tokens {
+			if t == word || tokenSimilar(t, word) {
+				hits += n
+			}
+		}
+
+		if least < 0 || hits < least {
+			least = hits
+		}
+	}
+
+	return least
+}
+
+// DetectLanguage returns the two letter code of the language of the page,
+// declared by the html lang attribute or the meta tags, or recognized by
+// the stop words of text. It returns an empty string when it is not known.
+func DetectLanguage(doc *goquery.Document, text string) string {
+	declared := []string{
+		doc.Find("html").First().AttrOr("lang", ""),
+		doc.Find(`meta[http-equiv="content-language" i]`).First().AttrOr("content", ""),
+		doc.Find(`meta[property="og:locale"]`).First().AttrOr("content", ""),
+	}
+
+	for _, lang := range declared {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if i := strings.IndexAny(lang, "-_,"); i >= 0 {
+			lang = lang[:i]
+		}
+
+		if len(lang) == 2 {
+			return lang
+		}
+	}
+
+	words := make(map[string]int)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		words[w]++
+	}
+
+	best, bestHits := "", 0
+
+	for lang, stopWords := range languageStopWords {
+		hits := 0
+		for _, w := range stopWords {
+			hits += words[w]
+		}
+
+		if hits > bestHits || (hits == bestHits && lang < best) {
+			best, bestHits = lang, hits
+		}
+	}
+
+	if bestHits < languageMinHits {
+		return ""
+	}
+
+	return best
+}
@@ -0,0 +1,107 @@
+package gmaps_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+const heatPumpPage = `<html lang="pl-PL"><head>
+<title>Pompy ciepła Bytom - montaż i serwis</title>
+<meta name="description" content="Montujemy pompy ciepła i fotowoltaikę w Bytomiu.">
+</head><body>
+<h1>Pompa ciepła dla Twojego domu</h1>
+<p>Instalujemy pompy ciepła powietrze-woda. Pompa ciepła to tanie ogrzewanie.</p>
+<script>var pompy = "pompy pompy pompy";</script>
+</body></html>`
+
+const shopPage = `<html><head><title>Sklep AGD - lodówki, pralki</title></head><body>
+<h1>Promocje na sprzęt AGD</h1>
+<p>W naszym sklepie znajdziesz pralki i lodówki. Oferujemy też dostawę do domu oraz montaż.
+Sprawdź, jak działa nasza promocja, i nie zwlekaj z zakupem. Jest też pompa do wody.</p>
+</body></html>`
+
+func Test_RelevanceTerms(t *testing.T) {
+	require.Equal(t, []string{"pompy", "ciepła", "bytom"}, gmaps.RelevanceTerms("Pompy ciepła w Bytom"))
+}
+
+func Test_QueryPhrase(t *testing.T) {
+	cities := []string{"Bytom", "Ruda Śląska", "Bielsko-Biała"}
+
+	require.Equal(t, "pompy ciepła", gmaps.QueryPhrase("pompy ciepła Bytom", cities))
+	require.Equal(t, "pompy ciepła", gmaps.QueryPhrase("pompy ciepła ruda slaska", cities))
+	require.Equal(t, "biuro podatkowe", gmaps.QueryPhrase("biuro podatkowe Bielsko-Biała", cities))
+	require.Equal(t, "pompy ciepła Zabrze", gmaps.QueryPhrase("pompy ciepła Zabrze", cities))
+	// a query made of a city only is kept
+	require.Equal(t, "Bytom", gmaps.QueryPhrase("Bytom", cities))
+
+	job := gmaps.NewGmapJob("", "pl", "pompy ciepła Bytom", 1, true, gmaps.WithRelevanceCities(cities))
+	require.Equal(t, []string{"pompy", "ciepła"}, job.Relevance.Terms)
+}
+
+func Test_ScoreRelevance(t *testing.T) {
+	terms := []string{"pompy", "ciepła"}
+
+	relevant := gmaps.ScoreRelevance(newDocument(t, heatPumpPage), terms)
+	require.Equal(t, "pl", relevant.Language)
+	require.Equal(t, terms, relevant.Matched)
+	// the words in the script are not counted, three in the text are short
+	// of the full body weight
+	require.InDelta(t, 0.92, relevant.Score, 0.001)
+
+	shop := gmaps.ScoreRelevance(newDocument(t, shopPage), terms)
+	// the language is recognized from the text
+	require.Equal(t, "pl", shop.Language)
+	require.Equal(t, []string{"pompy"}, shop.Matched)
+	require.Less(t, shop.Score, 0.1)
+
+	// a phrase needs all its words
+	phrase := gmaps.ScoreRelevance(newDocument(t, shopPage), []string{"pompy ciepła"})
+	require.Empty(t, phrase.Matched)
+	require.Zero(t, phrase.Score)
+}
+
+func Test_DetectLanguage(t *testing.T) {
+	page := `<html><head><meta property="og:locale" content="de_DE"></head><body></body></html>`
+	require.Equal(t, "de", gmaps.DetectLanguage(newDocument(t, page), ""))
+
+	page = `<html><body><p>We install heat pumps and solar panels for the homes of our customers in the area.</p></body></html>`
+	require.Equal(t, "en", gmaps.DetectLanguage(newDocument(t, page), "We install heat pumps and solar panels for the homes of our customers in the area."))
+
+	require.Empty(t, gmaps.DetectLanguage(newDocument(t, `<html><body>AGD</body></html>`), "AGD"))
+}
+
+func Test_EmailExtractJobMinRelevance(t *testing.T) {
+	cfg := gmaps.RelevanceConfig{Terms: []string{"pompy ciepła"}, MinScore: 0.5}
+
+	entry := &gmaps.Entry{Title: "Sklep AGD", WebSite: "https://agd.pl", SocialLinks: map[string]string{}}
+	job := gmaps.NewEmailJob("parent", entry, gmaps.WithEmailJobRelevance(cfg))
+
+	result, next, err := job.Process(context.Background(), &scrapemate.Response{Body: []byte(shopPage), Document: newDocument(t, shopPage)})
+	require.NoError(t, err)
+	require.Nil(t, result)
+	require.Empty(t, next)
+	require.False(t, job.UseInResults())
+
+	entry = &gmaps.Entry{Title: "Pompy Bytom", WebSite: "https://pompy.pl", SocialLinks: map[string]string{}}
+	job = gmaps.NewEmailJob("parent", entry, gmaps.WithEmailJobRelevance(cfg))
+
+	result, _, err = job.Process(context.Background(), &scrapemate.Response{Body: []byte(heatPumpPage), Document: newDocument(t, heatPumpPage)})
+	require.NoError(t, err)
+	require.Equal(t, entry, result)
+	require.True(t, job.UseInResults())
+
+	row := map[string]string{}
+	for i, header := range entry.CsvHeaders() {
+		row[header] = entry.CsvRow()[i]
+	}
+
+	require.Equal(t, "pl", row["language"])
+	// the phrase is found three times in the text, short of the full body weight
+	require.Equal(t, "0.92", row["relevance_score"])
+	require.Equal(t, "pompy ciepła", row["relevance_matched_terms"])
+}
@@ -0,0 +1,128 @@
+package gmaps
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+const (
+	defaultRenderConcurrency = 2
+	defaultRenderWait        = 2000 // milliseconds
+	renderIdleTimeout        = 10000
+	// renderMaxText is the amount of visible text below which a page with
+	// scripts is considered to be rendered by JavaScript.
+	renderMaxText = 500
+)
+
+var (
+	scriptRe   = regexp.MustCompile(`(?is)<script\b[^>]*>.*?</script>`)
+	styleRe    = regexp.MustCompile(`(?is)<style\b[^>]*>.*?</style>`)
+	tagRe      = regexp.MustCompile(`(?s)<[^>]*>`)
+	spaMarkers = [][]byte{
+		[]byte(`id="root"></div>`),
+		[]byte(`id="app"></div>`),
+		[]byte(`id="__next"`),
+		[]byte(`__NEXT_DATA__`),
+		[]byte(`data-reactroot`),
+		[]byte(`ng-version=`),
+		[]byte(`static.wixstatic.com`),
+		[]byte(`static.parastorage.com`),
+	}
+)
+
+// RenderConfig configures the fallback reading the rendered DOM of the
+// websites built with JavaScript.
+type RenderConfig struct {
+	// Concurrency is the number of pages rendered at once.
+	Concurrency int
+	// WaitMillis is the time given to the scripts after the network went
+	// idle.
+	WaitMillis float64
+}
+
+// Renderer reads the DOM of a page after its scripts have run. The browser
+// visit returns the HTML sent by the server, which is nearly empty for
+// single page applications. Rendering keeps the browser page busy for
+// longer, so it is done by a low priority job of its own, run after the
+// Google Maps place jobs and the other website visits but along with the
+// search jobs, and the number of pages rendered at once is limited. A render
+// job waiting for the host delay or for a slot holds its browser page. A nil
+// *Renderer renders nothing.
+type Renderer struct {
+	slots chan struct{}
+	wait  float64
+}
+
+func NewRenderer(cfg RenderConfig) *Renderer {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultRenderConcurrency
+	}
+
+	if cfg.WaitMillis == 0 {
+		cfg.WaitMillis = defaultRenderWait
+	}
+
+	return &Renderer{
+		slots: make(chan struct{}, cfg.Concurrency),
+		wait:  cfg.WaitMillis,
+	}
+}
+
+// Render returns the HTML of the rendered DOM of page, which must already
+// have been navigated to the website.
+func (r *Renderer) Render(ctx context.Context, page playwright.Page) ([]byte, error) {
+	select {
+	case r.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	defer func() { <-r.slots }()
+
+	// the page may keep a connection open, so a timeout is not an error
+	_ = page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+		State:   playwright.LoadStateNetworkidle,
+		Timeout: playwright.Float(renderIdleTimeout),
+	})
+
+	page.WaitForTimeout(r.wait)
+
+	content, err := page.Content()
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(content), nil
+}
+
+// NeedsRendering reports whether nothing can be extracted from the HTML
+// sent by the server and the page looks like it is built by JavaScript.
+func NeedsRendering(body []byte) bool {
+	if len(regexEmailExtractor(body)) > 0 || len(ExtractNIPs(body)) > 0 {
+		return false
+	}
+
+	return looksScriptHeavy(body)
+}
+
+func looksScriptHeavy(body []byte) bool {
+	scripts := scriptRe.FindAll(body, -1)
+	if len(scripts) == 0 {
+		return false
+	}
+
+	for _, marker := range spaMarkers {
+		if bytes.Contains(body, marker) {
+			return true
+		}
+	}
+
+	text := scriptRe.ReplaceAll(body, nil)
+	text = styleRe.ReplaceAll(text, nil)
+	text = tagRe.ReplaceAll(text, []byte(" "))
+
+	return len(bytes.Join(bytes.Fields(text), []byte(" "))) < renderMaxText
+}
@@ -0,0 +1,193 @@
+package gmaps_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/playwright-community/playwright-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+const spaPage = `<html><head><script src="/static/js/main.js"></script></head>
+<body><noscript>You need to enable JavaScript to run this app.</noscript><div id="root"></div></body></html>`
+
+const renderedPage = `<html><body><div id="root"><h1>Pompy Kowalski</h1>
+<a href="mailto:biuro@kowalski.pl">biuro@kowalski.pl</a><p>NIP: 526-025-02-74</p></div></body></html>`
+
+// renderPage is a browser page whose DOM is renderedPage. It records how
+// many pages were rendered at once.
+type renderPage struct {
+	playwright.Page
+	active, peak int32
+}
+
+func (p *renderPage) WaitForLoadState(...playwright.PageWaitForLoadStateOptions) error {
+	return nil
+}
+
+func (p *renderPage) WaitForTimeout(float64) {
+	active := atomic.AddInt32(&p.active, 1)
+	defer atomic.AddInt32(&p.active, -1)
+
+	for {
+		peak := atomic.LoadInt32(&p.peak)
+		if active <= peak || atomic.CompareAndSwapInt32(&p.peak, peak, active) {
+			break
+		}
+	}
+
+	time.Sleep(10 * time.Millisecond)
+}
+
+func (p *renderPage) Content() (string, error) {
+	return renderedPage, nil
+}
+
+func Test_NeedsRendering(t *testing.T) {
+	require.True(t, gmaps.NeedsRendering([]byte(spaPage)))
+	require.False(t, gmaps.NeedsRendering([]byte(renderedPage)))
+
+	// a page without scripts has nothing more to show
+	require.False(t, gmaps.NeedsRendering([]byte(`<html><body><p>Zapraszamy</p></body></html>`)))
+
+	// a page with plenty of text is not rendered even when it has scripts
+	text := strings.Repeat("<p>Oferujemy montaż i serwis pomp ciepła.</p>", 30)
+	require.False(t, gmaps.NeedsRendering([]byte(`<html><head><script src="/app.js"></script></head><body>`+text+`</body></html>`)))
+
+	// nearly empty page built by scripts
+	require.True(t, gmaps.NeedsRendering([]byte(`<html><body><script>document.write("...")</script><div class="x"></div></body></html>`)))
+}
+
+func Test_RendererRender(t *testing.T) {
+	page := &renderPage{}
+	renderer := gmaps.NewRenderer(gmaps.RenderConfig{Concurrency: 2})
+
+	body, err := renderer.Render(context.Background(), page)
+	require.NoError(t, err)
+	require.Equal(t, renderedPage, string(body))
+	require.False(t, gmaps.NeedsRendering(body))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := renderer.Render(context.Background(), page)
+			require.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, int32(2), atomic.LoadInt32(&page.peak))
+}
+
+func Test_RendererRenderCanceled(t *testing.T) {
+	renderer := gmaps.NewRenderer(gmaps.RenderConfig{Concurrency: 1})
+
+	// the only slot is taken by a page which never finishes rendering
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+
+	go func() {
+		_, _ = renderer.Render(ctx, &blockedPage{started: started, done: ctx.Done()})
+	}()
+
+	<-started
+
+	waiting, cancelWaiting := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelWaiting()
+
+	_, err := renderer.Render(waiting, &renderPage{})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+type blockedPage struct {
+	renderPage
+	started chan struct{}
+	done    <-chan struct{}
+}
+
+func (p *blockedPage) WaitForTimeout(float64) {
+	close(p.started)
+	<-p.done
+}
+
+// spaSite is a browser page of a website built by scripts: its HTML is
+// spaPage and its rendered DOM renderedPage.
+type spaSite struct {
+	renderPage
+	visits int
+}
+
+func (p *spaSite) Goto(url string, _ ...playwright.PageGotoOptions) (playwright.Response, error) {
+	p.visits++
+
+	return &navigation{
+		url:     url,
+		status:  http.StatusOK,
+		headers: map[string]string{},
+		request: &navigationRequest{url: url},
+		body:    spaPage,
+	}, nil
+}
+
+func (p *spaSite) SetExtraHTTPHeaders(map[string]string) error {
+	return nil
+}
+
+func Test_EmailExtractJobRender(t *testing.T) {
+	ctx := context.Background()
+	page := &spaSite{}
+	enrichment := &gmaps.Enrichment{Renderer: gmaps.NewRenderer(gmaps.RenderConfig{WaitMillis: 1})}
+	entry := &gmaps.Entry{Title: "Pompy Kowalski", WebSite: "https://kowalski.pl", SocialLinks: map[string]string{}}
+
+	job := gmaps.NewEmailJob("place", entry, gmaps.WithEmailJobEnrichment(enrichment))
+
+	resp := job.BrowserActions(ctx, page)
+	require.NoError(t, resp.Error)
+	require.Zero(t, atomic.LoadInt32(&page.peak))
+
+	// the page is not rendered by the job holding it, it is handed over to
+	// a low priority job
+	resp.Document = newDocument(t, string(resp.Body))
+
+	data, next, err := job.Process(ctx, &resp)
+	require.NoError(t, err)
+	require.Nil(t, data)
+	require.False(t, job.UseInResults())
+	require.Len(t, next, 1)
+
+	render, ok := next[0].(*gmaps.EmailExtractJob)
+	require.True(t, ok)
+	require.True(t, render.Render)
+	require.Equal(t, scrapemate.PriorityLow, render.GetPriority())
+	require.Equal(t, job.ID, render.GetParentID())
+
+	resp = render.BrowserActions(ctx, page)
+	require.NoError(t, resp.Error)
+	require.Equal(t, renderedPage, string(resp.Body))
+	require.Equal(t, 2, page.visits)
+
+	resp.Document = newDocument(t, string(resp.Body))
+
+	data, next, err = render.Process(ctx, &resp)
+	require.NoError(t, err)
+	require.Empty(t, next)
+	require.Same(t, entry, data)
+	require.Equal(t, []string{"biuro@kowalski.pl"}, entry.Emails)
+	require.True(t, entry.Website.Rendered)
+}
@@ -0,0 +1,441 @@
+package gmaps
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SourceSchemaOrg attributes data read from the schema.org JSON-LD or
+// microdata of the website.
+const SourceSchemaOrg = "schema.org"
+
+// businessTypes are the schema.org types describing the business itself.
+// LocalBusiness has hundreds of subtypes, so nodes of other types are
+// accepted as well when they carry contact data.
+var businessTypes = map[string]bool{
+	"organization":        true,
+	"corporation":         true,
+	"localbusiness":       true,
+	"store":               true,
+	"restaurant":          true,
+	"professionalservice": true,
+}
+
+// skippedTypes never describe the business.
+var skippedTypes = map[string]bool{
+	"website":        true,
+	"webpage":        true,
+	"breadcrumblist": true,
+	"imageobject":    true,
+	"person":         true,
+	"searchaction":   true,
+	"listitem":       true,
+	"postaladdress":  true,
+	"contactpoint":   true,
+}
+
+// StructuredData is the business data found in the schema.org markup of a
+// website.
+type StructuredData struct {
+	Name         string
+	Emails       []string
+	Telephone    string
+	Street       string
+	PostalCode   string
+	City         string
+	SameAs       []string
+	OpeningHours []string
+	VATID        string
+}
+
+// ExtractStructuredData reads the LocalBusiness and Organization nodes of the
+// JSON-LD scripts and the microdata of doc. When there are several nodes the
+// first non empty value of every field wins. It returns nil when there is
+// nothing.
+func ExtractStructuredData(doc *goquery.Document) *StructuredData {
+	var sd StructuredData
+
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		var v any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(s.Text())), &v); err != nil {
+			return
+		}
+
+		for _, node := range jsonLDNodes(v) {
+			sd.mergeJSONLD(node)
+		}
+	})
+
+	doc.Find("[itemscope][itemtype]").Each(func(_ int, s *goquery.Selection) {
+		hasContact := microdataProps(s).Filter("[itemprop~=telephone],[itemprop~=email]").Length() > 0
+
+		if isBusinessType(schemaType(s.AttrOr("itemtype", "")), hasContact) {
+			sd.mergeMicrodata(s)
+		}
+	})
+
+	if sd.empty() {
+		return nil
+	}
+
+	return &sd
+}
+
+// jsonLDNodes returns the business nodes of a JSON-LD document, looking
+// into arrays and @graph.
+func jsonLDNodes(v any) []map[string]any {
+	var nodes []map[string]any
+
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			nodes = append(nodes, jsonLDNodes(item)...)
+		}
+	case map[string]any:
+		if graph, ok := v["@graph"]; ok {
+			nodes = append(nodes, jsonLDNodes(graph)...)
+		}
+
+		_, hasContact := v["telephone"]
+		if _, ok := v["email"]; ok {
+			hasContact = true
+		}
+
+		for _, t := range jsonStrings(v["@type"]) {
+			if isBusinessType(schemaType(t), hasContact) {
+				nodes = append(nodes, v)
+
+				break
+			}
+		}
+	}
+
+	return nodes
+}
+
+func schemaType(t string) string {
+	t = strings.TrimRight(t, "/")
+	if i := strings.LastIndexAny(t, "/#:"); i >= 0 {
+		t = t[i+1:]
+	}
+
+	return strings.ToLower(t)
+}
+
+func isBusinessType(t string, hasContact bool) bool {
+	if t == "" || skippedTypes[t] {
+		return false
+	}
+
+	return businessTypes[t] || strings.HasSuffix(t, "business") || hasContact
+}
+
+func (sd *StructuredData) mergeJSONLD(node map[string]any) {
+	setFirst(&sd.Name, jsonString(node["legalName"]), jsonString(node["name"]))
+	setFirst(&sd.Telephone, jsonString(node["telephone"]))
+	setFirst(&sd.VATID, jsonString(node["vatID"]), jsonString(node["taxID"]))
+
+	sd.Emails = appendUnique(sd.Emails, jsonStrings(node["email"])...)
+	sd.SameAs = appendUnique(sd.SameAs, jsonStrings(node["sameAs"])...)
+	sd.OpeningHours = appendUnique(sd.OpeningHours, jsonStrings(node["openingHours"])...)
+
+	for _, spec := range jsonObjects(node["openingHoursSpecification"]) {
+		sd.OpeningHours = appendUnique(sd.OpeningHours, openingHoursSpec(spec))
+	}
+
+	for _, point := range jsonObjects(node["contactPoint"]) {
+		setFirst(&sd.Telephone, jsonString(point["telephone"]))
+		sd.Emails = appendUnique(sd.Emails, jsonStrings(point["email"])...)
+	}
+
+	if address, ok := node["address"].(string); ok {
+		setFirst(&sd.Street, address)
+	}
+
+	for _, address := range jsonObjects(node["address"]) {
+		if sd.Street != "" || sd.City != "" {
+			break
+		}
+
+		sd.Street = jsonString(address["streetAddress"])
+		sd.PostalCode = jsonString(address["postalCode"])
+		sd.City = jsonString(address["addressLocality"])
+	}
+}
+
+// openingHoursSpec renders an OpeningHoursSpecification in the openingHours
+// format, for example "Mo,Tu 08:00-16:00".
+func openingHoursSpec(spec map[string]any) string {
+	days := jsonStrings(spec["dayOfWeek"])
+	for i := range days {
+		days[i] = schemaType(days[i])
+		if len(days[i]) > 2 {
+			days[i] = strings.ToUpper(days[i][:1]) + days[i][1:2]
+		}
+	}
+
+	opens, closes := jsonString(spec["opens"]), jsonString(spec["closes"])
+	if len(days) == 0 || opens == "" {
+		return ""
+	}
+
+	return strings.Join(days, ",") + " " + trimSeconds(opens) + "-" + trimSeconds(closes)
+}
+
+func trimSeconds(t string) string {
+	if len(t) == len("08:00:00") {
+		return t[:5]
+	}
+
+	return t
+}
+
+func (sd *StructuredData) mergeMicrodata(item *goquery.Selection) {
+	props := map[string][]string{}
+
+	microdataProps(item).Each(func(_ int, s *goquery.Selection) {
+		for _, name := range strings.Fields(s.AttrOr("itemprop", "")) {
+			if name == "address" && s.Is("[itemscope]") {
+				microdataProps(s).Each(func(_ int, a *goquery.Selection) {
+					key := "address." + a.AttrOr("itemprop", "")
+					props[key] = append(props[key], microdataValue(a))
+				})
+
+				continue
+			}
+
+			props[name] = append(props[name], microdataValue(s))
+		}
+	})
+
+	first := func(name string) string {
+		if len(props[name]) > 0 {
+			return props[name][0]
+		}
+
+		return ""
+	}
+
+	setFirst(&sd.Name, first("legalName"), first("name"))
+	setFirst(&sd.Telephone, first("telephone"))
+	setFirst(&sd.VATID, first("vatID"), first("taxID"))
+
+	for _, email := range props["email"] {
+		sd.Emails = appendUnique(sd.Emails, strings.TrimPrefix(email, "mailto:"))
+	}
+
+	sd.SameAs = appendUnique(sd.SameAs, props["sameAs"]...)
+	sd.OpeningHours = appendUnique(sd.OpeningHours, props["openingHours"]...)
+
+	if sd.Street == "" && sd.City == "" {
+		sd.Street = first("address.streetAddress")
+		sd.PostalCode = first("address.postalCode")
+		sd.City = first("address.addressLocality")
+	}
+
+	if sd.Street == "" {
+		sd.Street = first("address")
+	}
+}
+
+// microdataProps returns the properties of item, leaving out the
+// properties of the items nested in it.
+func microdataProps(item *goquery.Selection) *goquery.Selection {
+	root := item.Get(0)
+
+	return item.Find("[itemprop]").FilterFunction(func(_ int, s *goquery.Selection) bool {
+		parent := s.Parent().Closest("[itemscope]")
+
+		return parent.Length() > 0 && parent.Get(0) == root
+	})
+}
+
+func microdataValue(s *goquery.Selection) string {
+	for _, attr := range []string{"content", "href", "src", "datetime"} {
+		if v, ok := s.Attr(attr); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+
+	return strings.Join(strings.Fields(s.Text()), " ")
+}
+
+func (sd *StructuredData) empty() bool {
+	return sd.Name == "" && len(sd.Emails) == 0 && sd.Telephone == "" && sd.Street == "" &&
+		sd.City == "" && len(sd.SameAs) == 0 && len(sd.OpeningHours) == 0 && sd.VATID == ""
+}
+
+// applyStructuredData fills the entry with the structured data of its
+// website. Structured data is preferred over what the regular expressions
+// found on the page, but the phone and address from Google Maps are kept.
+func (e *Entry) applyStructuredData(sd *StructuredData) {
+	if sd == nil {
+		return
+	}
+
+	if len(sd.Emails) > 0 {
+		var emails []string
+
+		for _, email := range sd.Emails {
+			if valid, err := getValidEmail(strings.TrimPrefix(email, "mailto:")); err == nil {
+				emails = appendUnique(emails, valid)
+			}
+		}
+
+		if len(emails) > 0 {
+			e.Emails = appendUnique(emails, e.Emails...)
+			e.setFieldSource("emails", SourceSchemaOrg)
+		}
+	}
+
+	for _, link := range sd.SameAs {
+		for _, network := range []string{"facebook", "instagram", "twitter"} {
+			if strings.Contains(link, network) {
+				e.SocialLinks[network] = link
+				e.setFieldSource(network, SourceSchemaOrg)
+			}
+		}
+	}
+
+	if e.Phone == "" && sd.Telephone != "" {
+		e.Phone = sd.Telephone
+		e.setFieldSource("phone", SourceSchemaOrg)
+	}
+
+	if e.Address.Street == "" && sd.Street != "" {
+		e.Address.Street = sd.Street
+		e.setFieldSource("address", SourceSchemaOrg)
+	}
+
+	if e.City == "" && sd.City != "" {
+		e.City = sd.City
+		e.setFieldSource("city", SourceSchemaOrg)
+	}
+
+	if len(sd.OpeningHours) > 0 {
+		e.OpeningHours = sd.OpeningHours
+		e.setFieldSource("opening_hours", SourceSchemaOrg)
+	}
+
+	if nip := cleanNIP(strings.TrimPrefix(strings.ToUpper(cleanNIP(sd.VATID)), "PL")); ValidNIP(nip) {
+		// the markup states the number of the business, so it goes before a
+		// number found in the page text with the same confidence
+		candidates := []IDCandidate{{Value: nip, Confidence: 1, Source: SourceSchemaOrg}}
+		for _, c := range e.NIPCandidates {
+			if c.Value != nip {
+				candidates = append(candidates, c)
+			}
+		}
+
+		sortCandidates(candidates)
+		e.NIPCandidates = candidates
+		e.NIP = candidates[0].Value
+		e.setFieldSource("nip", candidates[0].Source)
+	}
+}
+
+// setFieldSource records where the value of a field came from.
+func (e *Entry) setFieldSource(field, source string) {
+	if e.FieldSources == nil {
+		e.FieldSources = make(map[string]string)
+	}
+
+	e.FieldSources[field] = source
+}
+
+func setFirst(dst *string, values ...string) {
+	if *dst != "" {
+		return
+	}
+
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			*dst = v
+
+			return
+		}
+	}
+}
+
+func appendUnique(dst []string, values ...string) []string {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		found := false
+
+		for _, existing := range dst {
+			if strings.EqualFold(existing, v) {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			dst = append(dst, v)
+		}
+	}
+
+	return dst
+}
+
+func jsonString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case []any:
+		if len(v) > 0 {
+			return jsonString(v[0])
+		}
+	case map[string]any:
+		// a reference such as {"@id": ...} or a nested value
+		if value, ok := v["@value"]; ok {
+			return jsonString(value)
+		}
+	}
+
+	return ""
+}
+
+func jsonStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{strings.TrimSpace(v)}
+	case []any:
+		var values []string
+
+		for _, item := range v {
+			if s := jsonString(item); s != "" {
+				values = append(values, s)
+			}
+		}
+
+		return values
+	}
+
+	return nil
+}
+
+func jsonObjects(v any) []map[string]any {
+	switch v := v.(type) {
+	case map[string]any:
+		return []map[string]any{v}
+	case []any:
+		var objects []map[string]any
+
+		for _, item := range v {
+			if o, ok := item.(map[string]any); ok {
+				objects = append(objects, o)
+			}
+		}
+
+		return objects
+	}
+
+	return nil
+}
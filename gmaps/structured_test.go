@@ -0,0 +1,123 @@
+package gmaps_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+const jsonLDPage = `<html><head>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@graph": [
+  {"@type": "WebSite", "name": "Strona", "url": "https://example.com"},
+  {"@type": ["Dentist"], "name": "Gabinet Uśmiech", "legalName": "Uśmiech Sp. z o.o.",
+   "email": "mailto:biuro@usmiech.pl", "telephone": "+48 22 123 45 67", "vatID": "PL 526-025-02-74",
+   "sameAs": ["https://www.facebook.com/usmiech", "https://www.instagram.com/usmiech"],
+   "address": {"@type": "PostalAddress", "streetAddress": "ul. Długa 5", "postalCode": "00-001", "addressLocality": "Warszawa"},
+   "openingHoursSpecification": [
+     {"@type": "OpeningHoursSpecification", "dayOfWeek": ["https://schema.org/Monday", "https://schema.org/Tuesday"], "opens": "08:00:00", "closes": "16:00:00"}
+   ]}
+]}
+</script>
+</head><body>
+<a href="mailto:kontakt@usmiech.pl">kontakt</a>
+<a href="https://facebook.com/stary-profil">fb</a>
+<p>NIP: 1234563218</p>
+</body></html>`
+
+const microdataPage = `<html><body>
+<div itemscope itemtype="https://schema.org/LocalBusiness">
+  <span itemprop="name">Pompy Kowalski</span>
+  <a itemprop="email" href="mailto:pompy@kowalski.pl">napisz</a>
+  <span itemprop="telephone">601 234 567</span>
+  <meta itemprop="openingHours" content="Mo-Fr 07:00-15:00">
+  <div itemprop="address" itemscope itemtype="https://schema.org/PostalAddress">
+    <span itemprop="streetAddress">ul. Krótka 1</span>
+    <span itemprop="postalCode">30-001</span> <span itemprop="addressLocality">Kraków</span>
+  </div>
+  <div itemprop="employee" itemscope itemtype="https://schema.org/Person">
+    <span itemprop="telephone">700 000 000</span>
+  </div>
+</div>
+</body></html>`
+
+func newDocument(t *testing.T, html string) *goquery.Document {
+	t.Helper()
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	require.NoError(t, err)
+
+	return doc
+}
+
+func Test_ExtractStructuredDataJSONLD(t *testing.T) {
+	sd := gmaps.ExtractStructuredData(newDocument(t, jsonLDPage))
+	require.NotNil(t, sd)
+
+	require.Equal(t, "Uśmiech Sp. z o.o.", sd.Name)
+	require.Equal(t, []string{"mailto:biuro@usmiech.pl"}, sd.Emails)
+	require.Equal(t, "+48 22 123 45 67", sd.Telephone)
+	require.Equal(t, "ul. Długa 5", sd.Street)
+	require.Equal(t, "00-001", sd.PostalCode)
+	require.Equal(t, "Warszawa", sd.City)
+	require.Equal(t, []string{"Mo,Tu 08:00-16:00"}, sd.OpeningHours)
+	require.Equal(t, "PL 526-025-02-74", sd.VATID)
+	require.Len(t, sd.SameAs, 2)
+}
+
+func Test_ExtractStructuredDataMicrodata(t *testing.T) {
+	sd := gmaps.ExtractStructuredData(newDocument(t, microdataPage))
+	require.NotNil(t, sd)
+
+	require.Equal(t, "Pompy Kowalski", sd.Name)
+	require.Equal(t, []string{"pompy@kowalski.pl"}, sd.Emails)
+	// the phone of the nested Person is not the phone of the business
+	require.Equal(t, "601 234 567", sd.Telephone)
+	require.Equal(t, "ul. Krótka 1", sd.Street)
+	require.Equal(t, "Kraków", sd.City)
+	require.Equal(t, []string{"Mo-Fr 07:00-15:00"}, sd.OpeningHours)
+}
+
+func Test_ExtractStructuredDataNone(t *testing.T) {
+	require.Nil(t, gmaps.ExtractStructuredData(newDocument(t, `<html><body><p>nic</p></body></html>`)))
+}
+
+func Test_EmailExtractJobStructuredData(t *testing.T) {
+	entry := &gmaps.Entry{
+		Title:       "Gabinet Uśmiech",
+		WebSite:     "https://usmiech.pl",
+		Phone:       "22 999 99 99",
+		SocialLinks: map[string]string{},
+	}
+
+	resp := &scrapemate.Response{Body: []byte(jsonLDPage), Document: newDocument(t, jsonLDPage)}
+
+	result, _, err := gmaps.NewEmailJob("parent", entry).Process(context.Background(), resp)
+	require.NoError(t, err)
+	require.Equal(t, entry, result)
+
+	// structured data goes first, the regular expressions fill in the rest
+	require.Equal(t, []string{"biuro@usmiech.pl", "kontakt@usmiech.pl"}, entry.Emails)
+	require.Equal(t, "https://www.facebook.com/usmiech", entry.SocialLinks["facebook"])
+	require.Equal(t, "5260250274", entry.NIP)
+	// the Google Maps phone is kept
+	require.Equal(t, "22 999 99 99", entry.Phone)
+	require.Equal(t, "ul. Długa 5", entry.Address.Street)
+	require.Equal(t, []string{"Mo,Tu 08:00-16:00"}, entry.OpeningHours)
+
+	require.Equal(t, map[string]string{
+		"emails":        gmaps.SourceSchemaOrg,
+		"facebook":      gmaps.SourceSchemaOrg,
+		"instagram":     gmaps.SourceSchemaOrg,
+		"nip":           gmaps.SourceSchemaOrg,
+		"address":       gmaps.SourceSchemaOrg,
+		"city":          gmaps.SourceSchemaOrg,
+		"opening_hours": gmaps.SourceSchemaOrg,
+	}, entry.FieldSources)
+}
@@ -0,0 +1,229 @@
+package gmaps
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gosom/scrapemate"
+)
+
+const (
+	viesDefaultURL     = "https://ec.europa.eu/taxation_customs/vies/rest-api"
+	viesDefaultTimeout = 15 * time.Second
+
+	envVIESURL     = "VIES_URL"
+	envVIESTimeout = "VIES_TIMEOUT"
+
+	// SourceVIES attributes data confirmed by the EU VIES service.
+	SourceVIES = "vies"
+)
+
+// VATStatus is the result of a VIES check of the NIP of the entry.
+type VATStatus struct {
+	CountryCode string    `json:"country_code"`
+	VATNumber   string    `json:"vat_number"`
+	Valid       bool      `json:"valid"`
+	Name        string    `json:"name"`
+	Address     string    `json:"address"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// VIESClient checks VAT numbers against the EU VIES service.
+type VIESClient interface {
+	CheckVAT(ctx context.Context, countryCode, vatNumber string) (*VATStatus, error)
+}
+
+// VIESConfig configures the VIES client.
+type VIESConfig struct {
+	URL     string
+	Timeout time.Duration
+	// Limiter limits the rate of the requests and retries them, nil
+	// sends them as they come.
+	Limiter *RegistryLimiter
+}
+
+// LoadVIESConfig reads the VIES_URL and VIES_TIMEOUT settings from a file in
+// the .env format, or from the environment when path is empty.
+func LoadVIESConfig(path string) (VIESConfig, error) {
+	values, err := readSettings(path, envVIESURL, envVIESTimeout)
+	if err != nil {
+		return VIESConfig{}, err
+	}
+
+	cfg := VIESConfig{URL: values[envVIESURL]}
+
+	if cfg.Timeout, err = durationSetting(values, envVIESTimeout, viesDefaultTimeout); err != nil {
+		return VIESConfig{}, err
+	}
+
+	return cfg, nil
+}
+
+var _ VIESClient = (*VIESRESTClient)(nil)
+
+// VIESRESTClient uses the REST API of VIES.
+type VIESRESTClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewVIESRESTClient(cfg VIESConfig) *VIESRESTClient {
+	if cfg.URL == "" {
+		cfg.URL = viesDefaultURL
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = viesDefaultTimeout
+	}
+
+	return &VIESRESTClient{
+		baseURL: strings.TrimRight(cfg.URL, "/"),
+		client:  registryHTTPClient(cfg.Timeout, cfg.Limiter),
+	}
+}
+
+func (c *VIESRESTClient) CheckVAT(ctx context.Context, countryCode, vatNumber string) (*VATStatus, error) {
+	payload, err := json.Marshal(map[string]string{
+		"countryCode": countryCode,
+		"vatNumber":   vatNumber,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/check-vat-number", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vies: unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var viesResponse struct {
+		CountryCode string `json:"countryCode"`
+		VATNumber   string `json:"vatNumber"`
+		Valid       bool   `json:"valid"`
+		Name        string `json:"name"`
+		Address     string `json:"address"`
+		UserError   string `json:"userError"`
+	}
+
+	if err := json.Unmarshal(body, &viesResponse); err != nil {
+		return nil, fmt.Errorf("vies: %w", err)
+	}
+
+	// the member state services are often unavailable, such answers say
+	// nothing about the number
+	if viesResponse.UserError != "" && viesResponse.UserError != "VALID" && viesResponse.UserError != "INVALID" {
+		return nil, fmt.Errorf("vies: %s", viesResponse.UserError)
+	}
+
+	return &VATStatus{
+		CountryCode: countryCode,
+		VATNumber:   vatNumber,
+		Valid:       viesResponse.Valid,
+		Name:        viesValue(viesResponse.Name),
+		Address:     viesValue(viesResponse.Address),
+		CheckedAt:   time.Now().UTC(),
+	}, nil
+}
+
+// viesValue drops the "---" placeholder VIES returns for hidden data.
+func viesValue(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "---" {
+		return ""
+	}
+
+	return s
+}
+
+// NewCachedVIESClient keeps the answers of next, the invalid numbers too,
+// in the disk cache, so a rerun does not check the same numbers again.
+// Errors are not cached, so a number is checked again after the service was
+// unavailable.
+func NewCachedVIESClient(next VIESClient, cache *DiskCache) VIESClient {
+	return &cachedVIESClient{next: next, cache: cache}
+}
+
+type cachedVIESClient struct {
+	next  VIESClient
+	cache *DiskCache
+}
+
+func (c *cachedVIESClient) CheckVAT(ctx context.Context, countryCode, vatNumber string) (*VATStatus, error) {
+	key := countryCode + vatNumber
+	log := scrapemate.GetLoggerFromContext(ctx)
+
+	var status VATStatus
+
+	ok, err := c.cache.Get(SourceVIES, key, &status)
+	if err != nil {
+		log.Error("cache read failed", "source", SourceVIES, "error", err)
+	}
+
+	if ok {
+		return &status, nil
+	}
+
+	checked, err := c.next.CheckVAT(ctx, countryCode, vatNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.cache.Set(SourceVIES, key, checked); err != nil {
+		log.Error("cache write failed", "source", SourceVIES, "error", err)
+	}
+
+	return checked, nil
+}
+
+// NewVIESEnricher checks the NIP of the entry as a Polish VAT number.
+func NewVIESEnricher(client VIESClient) Enricher {
+	return &viesEnricher{client: client}
+}
+
+type viesEnricher struct {
+	client VIESClient
+}
+
+func (v *viesEnricher) Name() string {
+	return SourceVIES
+}
+
+func (v *viesEnricher) Enrich(ctx context.Context, e *Entry) error {
+	if e.NIP == "" {
+		return nil
+	}
+
+	status, err := v.client.CheckVAT(ctx, "PL", cleanNIP(e.NIP))
+	if err != nil {
+		return err
+	}
+
+	e.VAT = status
+
+	return nil
+}
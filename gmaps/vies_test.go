@@ -0,0 +1,150 @@
+package gmaps_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+// newVIESStub answers like the VIES REST API. The number 0000000000 makes
+// it report an unavailable member state service.
+func newVIESStub(t *testing.T, calls *int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+
+		if r.URL.Path != "/check-vat-number" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		var req struct {
+			CountryCode string `json:"countryCode"`
+			VATNumber   string `json:"vatNumber"`
+		}
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		resp := map[string]any{
+			"countryCode": req.CountryCode,
+			"vatNumber":   req.VATNumber,
+			"valid":       req.VATNumber == "5260250274",
+			"name":        "---",
+			"address":     "---",
+		}
+
+		switch req.VATNumber {
+		case "5260250274":
+			resp["name"] = "POMPY KOWALSKI SP. Z O.O."
+			resp["address"] = "UL. DŁUGA 5, 00-001 WARSZAWA"
+			resp["userError"] = "VALID"
+		case "0000000000":
+			resp["userError"] = "MS_UNAVAILABLE"
+		default:
+			resp["userError"] = "INVALID"
+		}
+
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func Test_VIESRESTClient(t *testing.T) {
+	var calls int32
+
+	srv := newVIESStub(t, &calls)
+	defer srv.Close()
+
+	client := gmaps.NewVIESRESTClient(gmaps.VIESConfig{URL: srv.URL})
+
+	status, err := client.CheckVAT(context.Background(), "PL", "5260250274")
+	require.NoError(t, err)
+	require.True(t, status.Valid)
+	require.Equal(t, "POMPY KOWALSKI SP. Z O.O.", status.Name)
+
+	status, err = client.CheckVAT(context.Background(), "PL", "1234563218")
+	require.NoError(t, err)
+	require.False(t, status.Valid)
+	require.Empty(t, status.Name)
+
+	_, err = client.CheckVAT(context.Background(), "PL", "0000000000")
+	require.Error(t, err)
+}
+
+func Test_CachedVIESClient(t *testing.T) {
+	var calls int32
+
+	srv := newVIESStub(t, &calls)
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	cache, err := gmaps.NewDiskCache(dir, nil)
+	require.NoError(t, err)
+
+	client := gmaps.NewCachedVIESClient(gmaps.NewVIESRESTClient(gmaps.VIESConfig{URL: srv.URL}), cache)
+
+	for i := 0; i < 3; i++ {
+		status, err := client.CheckVAT(context.Background(), "PL", "5260250274")
+		require.NoError(t, err)
+		require.True(t, status.Valid)
+	}
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// a rerun reads the answer from the disk
+	cache, err = gmaps.NewDiskCache(dir, nil)
+	require.NoError(t, err)
+
+	client = gmaps.NewCachedVIESClient(gmaps.NewVIESRESTClient(gmaps.VIESConfig{URL: srv.URL}), cache)
+
+	status, err := client.CheckVAT(context.Background(), "PL", "5260250274")
+	require.NoError(t, err)
+	require.Equal(t, "POMPY KOWALSKI SP. Z O.O.", status.Name)
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// errors are not cached
+	for i := 0; i < 2; i++ {
+		_, err := client.CheckVAT(context.Background(), "PL", "0000000000")
+		require.Error(t, err)
+	}
+
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func Test_VIESEnricher(t *testing.T) {
+	var calls int32
+
+	srv := newVIESStub(t, &calls)
+	defer srv.Close()
+
+	enrichers := []gmaps.Enricher{
+		gmaps.NewVIESEnricher(gmaps.NewVIESRESTClient(gmaps.VIESConfig{URL: srv.URL})),
+	}
+
+	entry := &gmaps.Entry{Title: "Pompy Kowalski", NIP: "526-025-02-74"}
+
+	_, _, err := gmaps.NewRegistryJob("parent", entry, enrichers).Process(context.Background(), &scrapemate.Response{})
+	require.NoError(t, err)
+
+	require.NotNil(t, entry.VAT)
+	require.True(t, entry.VAT.Valid)
+	require.Equal(t, "5260250274", entry.VAT.VATNumber)
+
+	row := map[string]string{}
+	for i, header := range entry.CsvHeaders() {
+		row[header] = entry.CsvRow()[i]
+	}
+
+	require.Equal(t, "true", row["vat_valid"])
+	require.Equal(t, "POMPY KOWALSKI SP. Z O.O.", row["vat_name"])
+}
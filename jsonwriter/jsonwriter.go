@@ -0,0 +1,171 @@
+package jsonwriter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+// flusher is implemented by the buffered writers, such as bufio.Writer.
+type flusher interface {
+	Flush() error
+}
+
+// httpFlusher is implemented by http.ResponseWriter.
+type httpFlusher interface {
+	Flush()
+}
+
+// NewJSONWriter returns a writer of a JSON array of the results. The array
+// is closed once all the results have been received, so the output is
+// valid JSON only after the run.
+func NewJSONWriter(w io.Writer) scrapemate.ResultWriter {
+	return &jsonWriter{w: w}
+}
+
+type jsonWriter struct {
+	w io.Writer
+}
+
+func (j *jsonWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	if _, err := io.WriteString(j.w, "["); err != nil {
+		return err
+	}
+
+	sep := "\n"
+
+	for result := range in {
+		for _, item := range asSlice(result.Data) {
+			data, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+
+			if _, err := io.WriteString(j.w, sep); err != nil {
+				return err
+			}
+
+			if _, err := j.w.Write(data); err != nil {
+				return err
+			}
+
+			sep = ",\n"
+		}
+	}
+
+	_, err := io.WriteString(j.w, "\n]\n")
+
+	return err
+}
+
+// NewNDJSONWriter returns a writer of newline delimited JSON: one result per
+// line, each written at once and flushed, so that the output of a running
+// crawl can be followed and a file cut short by a crash is still valid up
+// to its last line.
+func NewNDJSONWriter(w io.Writer) scrapemate.ResultWriter {
+	return &ndjsonWriter{w: w}
+}
+
+type ndjsonWriter struct {
+	w io.Writer
+}
+
+func (n *ndjsonWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		for _, item := range asSlice(result.Data) {
+			data, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+
+			if _, err := n.w.Write(append(data, '\n')); err != nil {
+				return err
+			}
+
+			if err := flush(n.w); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func flush(w io.Writer) error {
+	switch f := w.(type) {
+	case flusher:
+		return f.Flush()
+	case httpFlusher:
+		f.Flush()
+	}
+
+	return nil
+}
+
+func asSlice(data any) []any {
+	if items, ok := data.([]any); ok {
+		return items
+	}
+
+	return []any{data}
+}
+
+// ReadEntries reads the entries written by the JSON or the NDJSON writer.
+func ReadEntries(r io.Reader) ([]*gmaps.Entry, error) {
+	br := bufio.NewReader(r)
+
+	first, err := firstByte(br)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(br)
+
+	var entries []*gmaps.Entry
+
+	if first == '[' {
+		err := dec.Decode(&entries)
+
+		return entries, err
+	}
+
+	for {
+		var e gmaps.Entry
+
+		err := dec.Decode(&e)
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+
+		if err != nil {
+			return entries, err
+		}
+
+		entries = append(entries, &e)
+	}
+}
+
+// firstByte returns the first byte of r which is not white space, without
+// consuming it.
+func firstByte(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, r.UnreadByte()
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package jsonwriter_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+	"github.com/wojciechkapala/google-maps-scraper/jsonwriter"
+)
+
+func results(entries ...*gmaps.Entry) <-chan scrapemate.Result {
+	in := make(chan scrapemate.Result, len(entries))
+	for _, e := range entries {
+		in <- scrapemate.Result{Data: e}
+	}
+
+	close(in)
+
+	return in
+}
+
+func Test_JSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := jsonwriter.NewJSONWriter(&buf).Run(context.Background(), results(
+		&gmaps.Entry{Title: "Pompy Kowalski"},
+		&gmaps.Entry{Title: "Biuro Nowak"},
+	))
+	require.NoError(t, err)
+
+	var entries []gmaps.Entry
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entries))
+	require.Len(t, entries, 2)
+	require.Equal(t, "Biuro Nowak", entries[1].Title)
+
+	// no results is an empty array
+	buf.Reset()
+
+	require.NoError(t, jsonwriter.NewJSONWriter(&buf).Run(context.Background(), results()))
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entries))
+	require.Empty(t, entries)
+}
+
+// countingWriter counts the flushes of a buffered writer.
+type countingWriter struct {
+	*bufio.Writer
+	flushes int
+}
+
+func (c *countingWriter) Flush() error {
+	c.flushes++
+
+	return c.Writer.Flush()
+}
+
+func Test_NDJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := &countingWriter{Writer: bufio.NewWriter(&buf)}
+
+	err := jsonwriter.NewNDJSONWriter(w).Run(context.Background(), results(
+		&gmaps.Entry{Title: "Pompy Kowalski"},
+		&gmaps.Entry{Title: "Biuro Nowak"},
+		&gmaps.Entry{Title: "Sklep AGD"},
+	))
+	require.NoError(t, err)
+
+	// every record is flushed as soon as it is written
+	require.Equal(t, 3, w.flushes)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+
+	for _, line := range lines {
+		var e gmaps.Entry
+
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		require.NotEmpty(t, e.Title)
+	}
+}
+
+func Test_ReadEntries(t *testing.T) {
+	for _, newWriter := range []func(io.Writer) scrapemate.ResultWriter{jsonwriter.NewJSONWriter, jsonwriter.NewNDJSONWriter} {
+		var buf bytes.Buffer
+
+		err := newWriter(&buf).Run(context.Background(), results(
+			&gmaps.Entry{Title: "Pompy Kowalski", Latitude: 50.348, Longitude: 18.915},
+			&gmaps.Entry{Title: "Biuro Nowak"},
+		))
+		require.NoError(t, err)
+
+		entries, err := jsonwriter.ReadEntries(&buf)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		require.Equal(t, "Pompy Kowalski", entries[0].Title)
+		require.Equal(t, 18.915, entries[0].Longitude)
+	}
+
+	entries, err := jsonwriter.ReadEntries(strings.NewReader("  \n"))
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
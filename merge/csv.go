@@ -0,0 +1,140 @@
+package merge
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/wojciechkapala/google-maps-scraper/csvwriter"
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+// ReadCSV reads the results written by the CSV writer. The columns are the
+// fields their headers name, the other columns (custom headers, CRM
+// profiles) are skipped. The separator is the one of ',', ';' and tab
+// which is most frequent in the header line.
+//
+// The entries are rebuilt from the fields a CSV file keeps: the query, the
+// name, the address, the contact details, the identifiers, the VAT status,
+// the opening hours and the location. The registry records and the website
+// details are only compared.
+func ReadCSV(r io.Reader) (*ResultSet, error) {
+	br := bufio.NewReader(r)
+
+	header, err := br.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	header = strings.TrimPrefix(header, "\ufeff")
+
+	cr := csv.NewReader(io.MultiReader(strings.NewReader(header), br))
+	cr.Comma = separator(header)
+
+	headers, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("empty csv file")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	for _, f := range csvwriter.Fields() {
+		known[f] = true
+	}
+
+	set := &ResultSet{}
+	columns := make([]string, len(headers))
+
+	for i, h := range headers {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if !known[h] {
+			continue
+		}
+
+		columns[i] = h
+		set.Fields = append(set.Fields, h)
+	}
+
+	if len(set.Fields) == 0 {
+		return nil, errors.New("no csv column is named after a field, use the default columns or the field names as headers")
+	}
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return set, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		values := make(map[string]string)
+
+		for i, f := range columns {
+			if f != "" {
+				values[f] = strings.TrimSpace(record[i])
+			}
+		}
+
+		set.add(entryFromValues(values), values)
+	}
+}
+
+func separator(header string) rune {
+	best, count := ',', strings.Count(header, ",")
+
+	for _, r := range []rune{';', '\t'} {
+		if n := strings.Count(header, string(r)); n > count {
+			best, count = r, n
+		}
+	}
+
+	return best
+}
+
+func entryFromValues(values map[string]string) *gmaps.Entry {
+	e := &gmaps.Entry{
+		Query:        values["query"],
+		Title:        values["title"],
+		Address:      gmaps.Address{Street: values["address"]},
+		City:         values["city"],
+		WebSite:      values["website"],
+		Phone:        values["phone"],
+		Emails:       split(values["emails"], ", "),
+		SocialLinks:  make(map[string]string),
+		NIP:          values["nip"],
+		REGON:        values["regon"],
+		KRS:          values["krs"],
+		OpeningHours: split(values["opening_hours"], "; "),
+	}
+
+	for _, network := range []string{"facebook", "instagram", "twitter"} {
+		if link := values[network]; link != "" {
+			e.SocialLinks[network] = link
+		}
+	}
+
+	if valid, err := strconv.ParseBool(values["vat_valid"]); err == nil {
+		e.VAT = &gmaps.VATStatus{Valid: valid, Name: values["vat_name"]}
+	}
+
+	e.Latitude, _ = strconv.ParseFloat(values["latitude"], 64)
+	e.Longitude, _ = strconv.ParseFloat(values["longitude"], 64)
+
+	return e
+}
+
+func split(s, sep string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, sep)
+}
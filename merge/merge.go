@@ -0,0 +1,309 @@
+package merge
+
+import (
+	"strings"
+
+	"github.com/wojciechkapala/google-maps-scraper/crm"
+	"github.com/wojciechkapala/google-maps-scraper/csvwriter"
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+// ignored are the fields which are not compared: the query a place was
+// found by, which differs between the queries of a run, and the response
+// time of its website, which differs every time it is measured.
+var ignored = map[string]bool{
+	"query":               true,
+	"website_response_ms": true,
+}
+
+// ResultSet is a set of results read back, from a file of a previous run
+// or of the current one.
+type ResultSet struct {
+	// Fields are the fields of the places known in the set: every field
+	// of the JSON results, the columns of a CSV file.
+	Fields  []string
+	Entries []*gmaps.Entry
+	values  []map[string]string
+}
+
+// NewResultSet returns the set of the entries, for example read from a
+// JSON file or a database, with every field known.
+func NewResultSet(entries []*gmaps.Entry) *ResultSet {
+	set := &ResultSet{Fields: csvwriter.Fields()}
+
+	for _, e := range entries {
+		set.add(e, fieldValues(e))
+	}
+
+	return set
+}
+
+func (s *ResultSet) add(e *gmaps.Entry, values map[string]string) {
+	s.Entries = append(s.Entries, e)
+	s.values = append(s.values, values)
+}
+
+// fieldValues returns the values of the entry by field, as they are
+// written to CSV.
+func fieldValues(e *gmaps.Entry) map[string]string {
+	values := map[string]string{"query": e.Query}
+
+	row := e.CsvRow()
+	for i, f := range e.CsvHeaders() {
+		values[f] = strings.TrimSpace(row[i])
+	}
+
+	return values
+}
+
+// FieldChange is a field of a place whose value changed.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Change is a place found in both runs with the fields which changed.
+type Change struct {
+	Entry  *gmaps.Entry  `json:"place"`
+	Fields []FieldChange `json:"fields"`
+}
+
+// Report is the comparison of the results of a run with the previous ones.
+type Report struct {
+	New       []*gmaps.Entry `json:"new"`
+	Changed   []Change       `json:"changed"`
+	Gone      []*gmaps.Entry `json:"disappeared"`
+	Unchanged int            `json:"unchanged"`
+	// Merged are the places of both runs, with the current values of the
+	// places found again: the previous places in their order, including
+	// those which disappeared, then the new ones.
+	Merged []*gmaps.Entry `json:"-"`
+}
+
+// Compare matches the places of the current results with the previous
+// ones and reports which are new, which changed and which disappeared.
+//
+// A place is matched by the first identifier which is unique in both sets:
+// its Google Maps link, its phone number, its name and address, its NIP.
+// The link is missing from a CSV file, chains share their NIP. Only the
+// fields known in both sets are compared, so a CSV file with a few columns
+// can be compared with a full JSON file. Places found by several queries
+// of a run are kept once, by their link or, without it, by their name,
+// address and phone.
+func Compare(previous, current *ResultSet) Report {
+	previous, current = previous.dedup(), current.dedup()
+
+	known := make(map[string]bool)
+	for _, f := range current.Fields {
+		known[f] = true
+	}
+
+	var fields []string
+
+	for _, f := range previous.Fields {
+		if known[f] {
+			fields = append(fields, f)
+		}
+	}
+
+	prevMatch := make([]int, len(previous.Entries))
+	curMatch := make([]int, len(current.Entries))
+
+	for i := range prevMatch {
+		prevMatch[i] = -1
+	}
+
+	for i := range curMatch {
+		curMatch[i] = -1
+	}
+
+	for _, key := range keys(fields) {
+		prevKeys := previous.index(key, prevMatch)
+
+		for k, ci := range current.index(key, curMatch) {
+			pi, ok := prevKeys[k]
+			if !ok || pi < 0 || ci < 0 {
+				continue
+			}
+
+			prevMatch[pi], curMatch[ci] = ci, pi
+		}
+	}
+
+	var report Report
+
+	for ci, e := range current.Entries {
+		pi := curMatch[ci]
+		if pi < 0 {
+			report.New = append(report.New, e)
+
+			continue
+		}
+
+		changes := diff(fields, previous.values[pi], current.values[ci])
+		if len(changes) == 0 {
+			report.Unchanged++
+
+			continue
+		}
+
+		report.Changed = append(report.Changed, Change{Entry: e, Fields: changes})
+	}
+
+	for pi, e := range previous.Entries {
+		if ci := prevMatch[pi]; ci >= 0 {
+			report.Merged = append(report.Merged, current.Entries[ci])
+
+			continue
+		}
+
+		report.Gone = append(report.Gone, e)
+		report.Merged = append(report.Merged, e)
+	}
+
+	report.Merged = append(report.Merged, report.New...)
+
+	return report
+}
+
+// dedup returns the set without the places found again by another query,
+// which share the link of the first one. The rows of a CSV file have no
+// link, a row is a place found again when it has the name, the address
+// and the phone of a row before it. The other fields, the relevance to
+// the query for one, can differ between the rows.
+func (s *ResultSet) dedup() *ResultSet {
+	out := &ResultSet{Fields: s.Fields}
+	seen := make(map[string]bool)
+
+	for i, e := range s.Entries {
+		key := placeLink(e)
+		if key == "" {
+			key = rowKey(s.values[i])
+		}
+
+		if key != "" {
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+		}
+
+		out.add(e, s.values[i])
+	}
+
+	return out
+}
+
+// index returns the places not matched yet by their key, -1 for a key
+// shared by several places of the set, matched or not.
+func (s *ResultSet) index(key func(*gmaps.Entry, map[string]string) string, matched []int) map[string]int {
+	idx := make(map[string]int)
+
+	for i, e := range s.Entries {
+		k := key(e, s.values[i])
+		if k == "" {
+			continue
+		}
+
+		if _, ok := idx[k]; ok {
+			idx[k] = -1
+
+			continue
+		}
+
+		idx[k] = i
+	}
+
+	for k, i := range idx {
+		if i >= 0 && matched[i] >= 0 {
+			delete(idx, k)
+		}
+	}
+
+	return idx
+}
+
+// keys returns the identifiers of a place, the most reliable first. The
+// name and address are only an identifier with the fields known in both
+// sets.
+func keys(fields []string) []func(*gmaps.Entry, map[string]string) string {
+	known := make(map[string]bool)
+	for _, f := range fields {
+		known[f] = true
+	}
+
+	name := func(_ *gmaps.Entry, values map[string]string) string {
+		if values["title"] == "" {
+			return ""
+		}
+
+		parts := []string{values["title"]}
+
+		for _, f := range []string{"address", "city"} {
+			if known[f] {
+				parts = append(parts, values[f])
+			}
+		}
+
+		return normalize(strings.Join(parts, "|"))
+	}
+
+	return []func(*gmaps.Entry, map[string]string) string{
+		func(e *gmaps.Entry, _ map[string]string) string {
+			return placeLink(e)
+		},
+		func(_ *gmaps.Entry, values map[string]string) string {
+			if values["phone"] == "" || !known["phone"] {
+				return ""
+			}
+
+			return crm.E164(values["phone"])
+		},
+		name,
+		func(_ *gmaps.Entry, values map[string]string) string {
+			if !known["nip"] {
+				return ""
+			}
+
+			return values["nip"]
+		},
+	}
+}
+
+// rowKey returns the name, the address and the phone of a place, empty
+// without a name.
+func rowKey(values map[string]string) string {
+	if values["title"] == "" {
+		return ""
+	}
+
+	return normalize(strings.Join([]string{values["title"], values["address"], values["city"], crm.E164(values["phone"])}, "|"))
+}
+
+// placeLink returns the Google Maps link of the place without its query
+// string, which changes between runs.
+func placeLink(e *gmaps.Entry) string {
+	link, _, _ := strings.Cut(e.Link, "?")
+
+	return link
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func diff(fields []string, previous, current map[string]string) []FieldChange {
+	var changes []FieldChange
+
+	for _, f := range fields {
+		if ignored[f] || previous[f] == current[f] {
+			continue
+		}
+
+		changes = append(changes, FieldChange{Field: f, Old: previous[f], New: current[f]})
+	}
+
+	return changes
+}
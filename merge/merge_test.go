@@ -0,0 +1,151 @@
+package merge_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/csvwriter"
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+	"github.com/wojciechkapala/google-maps-scraper/merge"
+)
+
+func writeCSV(t *testing.T, entries ...*gmaps.Entry) *bytes.Buffer {
+	t.Helper()
+
+	in := make(chan scrapemate.Result, len(entries))
+	for _, e := range entries {
+		in <- scrapemate.Result{Data: e}
+	}
+
+	close(in)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, csvwriter.NewCSVWriter(&buf, csvwriter.Config{Comma: ';', BOM: true}).Run(context.Background(), in))
+
+	return &buf
+}
+
+func Test_Compare(t *testing.T) {
+	previous, err := merge.ReadCSV(writeCSV(t,
+		&gmaps.Entry{Title: "Pompy Kowalski", Address: gmaps.Address{Street: "Dworcowa", Number: "1"}, City: "Bytom", Phone: "32 111 11 11", Emails: []string{"biuro@pompy.pl"}, NIP: "6262626262"},
+		&gmaps.Entry{Title: "Biuro Nowak", City: "Gliwice", Phone: "+48 32 222 22 22"},
+		&gmaps.Entry{Title: "Sklep AGD", City: "Gliwice", Phone: "32 333 33 33"},
+		&gmaps.Entry{Title: "Serwis Wiśniewski", Address: gmaps.Address{Street: "Rynek", Number: "5"}, City: "Zabrze", Phone: "32 444 44 44"},
+	))
+	require.NoError(t, err)
+	require.Len(t, previous.Entries, 4)
+	require.Equal(t, []string{"biuro@pompy.pl"}, previous.Entries[0].Emails)
+
+	unchanged := &gmaps.Entry{
+		Link:    "https://www.google.com/maps/place/kowalski?hl=pl",
+		Title:   "Pompy Kowalski",
+		Address: gmaps.Address{Street: "Dworcowa", Number: "1"},
+		City:    "Bytom",
+		Phone:   "32 111 11 11",
+		Emails:  []string{"biuro@pompy.pl"},
+		NIP:     "6262626262",
+	}
+
+	current := merge.NewResultSet([]*gmaps.Entry{
+		unchanged,
+		// found again by another query
+		{Query: "pompy ciepła Bytom", Link: "https://www.google.com/maps/place/kowalski?hl=en", Title: "Pompy Kowalski"},
+		// renamed, matched by its phone
+		{Link: "https://www.google.com/maps/place/nowak", Title: "Biuro Rachunkowe Nowak", City: "Gliwice", Phone: "32 222 22 22"},
+		// new phone, matched by its name and address
+		{Link: "https://www.google.com/maps/place/wisniewski", Title: "Serwis Wiśniewski", Address: gmaps.Address{Street: "Rynek", Number: "5"}, City: "Zabrze", Phone: "32 555 55 55"},
+		{Link: "https://www.google.com/maps/place/nowy", Title: "Klimatyzacja Zieliński", City: "Bytom"},
+	})
+
+	report := merge.Compare(previous, current)
+
+	require.Equal(t, 1, report.Unchanged)
+	require.Len(t, report.New, 1)
+	require.Equal(t, "Klimatyzacja Zieliński", report.New[0].Title)
+	require.Len(t, report.Gone, 1)
+	require.Equal(t, "Sklep AGD", report.Gone[0].Title)
+
+	require.Len(t, report.Changed, 2)
+	require.Equal(t, "Biuro Rachunkowe Nowak", report.Changed[0].Entry.Title)
+	require.Equal(t, []merge.FieldChange{
+		{Field: "title", Old: "Biuro Nowak", New: "Biuro Rachunkowe Nowak"},
+		{Field: "phone", Old: "+48 32 222 22 22", New: "32 222 22 22"},
+	}, report.Changed[0].Fields)
+	require.Equal(t, []merge.FieldChange{
+		{Field: "phone", Old: "32 444 44 44", New: "32 555 55 55"},
+	}, report.Changed[1].Fields)
+
+	// the previous order with the current values, then the new places
+	var titles []string
+	for _, e := range report.Merged {
+		titles = append(titles, e.Title)
+	}
+
+	require.Equal(t, []string{"Pompy Kowalski", "Biuro Rachunkowe Nowak", "Sklep AGD", "Serwis Wiśniewski", "Klimatyzacja Zieliński"}, titles)
+	require.Same(t, unchanged, report.Merged[0])
+}
+
+func Test_CompareSharedNIP(t *testing.T) {
+	// the branches of a chain share their NIP, which does not identify them
+	previous := merge.NewResultSet([]*gmaps.Entry{
+		{Title: "Pompy Kowalski Bytom", NIP: "6262626262"},
+		{Title: "Pompy Kowalski Gliwice", NIP: "6262626262"},
+	})
+	current := merge.NewResultSet([]*gmaps.Entry{
+		{Title: "Pompy Kowalski Zabrze", NIP: "6262626262"},
+		{Title: "Pompy Kowalski Gliwice", NIP: "6262626262"},
+	})
+
+	report := merge.Compare(previous, current)
+
+	require.Equal(t, 1, report.Unchanged)
+	require.Len(t, report.New, 1)
+	require.Equal(t, "Pompy Kowalski Zabrze", report.New[0].Title)
+	require.Len(t, report.Gone, 1)
+	require.Equal(t, "Pompy Kowalski Bytom", report.Gone[0].Title)
+}
+
+func Test_CompareCSVPlaceOfSeveralQueries(t *testing.T) {
+	// a CSV file has no links, the place found by two queries is in two
+	// rows scored against each query
+	kowalski := func(query string, score float64) *gmaps.Entry {
+		return &gmaps.Entry{
+			Query:     query,
+			Title:     "Pompy Kowalski",
+			Address:   gmaps.Address{Street: "Dworcowa", Number: "1"},
+			City:      "Bytom",
+			Phone:     "32 111 11 11",
+			Relevance: &gmaps.Relevance{Score: score},
+		}
+	}
+	nowak := &gmaps.Entry{Query: "pompy ciepła Bytom", Title: "Biuro Nowak", City: "Bytom", Phone: "32 222 22 22"}
+	// a branch of the chain sharing the phone of the head office is
+	// another place
+	branch := &gmaps.Entry{Query: "pompy ciepła Bytom", Title: "Pompy Kowalski", Address: gmaps.Address{Street: "Rynek", Number: "5"}, City: "Bytom", Phone: "32 111 11 11"}
+
+	read := func() *merge.ResultSet {
+		set, err := merge.ReadCSV(writeCSV(t, kowalski("pompy ciepła Bytom", 0.8), nowak, kowalski("klimatyzacja Bytom", 0.2), branch))
+		require.NoError(t, err)
+		require.Len(t, set.Entries, 4)
+
+		return set
+	}
+
+	report := merge.Compare(read(), read())
+
+	require.Empty(t, report.New)
+	require.Empty(t, report.Changed)
+	require.Empty(t, report.Gone)
+	require.Equal(t, 3, report.Unchanged)
+	require.Len(t, report.Merged, 3)
+}
+
+func Test_ReadCSVUnknownColumns(t *testing.T) {
+	_, err := merge.ReadCSV(bytes.NewBufferString("Nazwa;Telefon\nPompy;123\n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,84 @@
+package multiwriter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+)
+
+// bufferSize is the number of results an output may lag behind the others.
+const bufferSize = 100
+
+// Output is a result writer named in the errors, for example after its
+// format and path.
+type Output struct {
+	Name   string
+	Writer scrapemate.ResultWriter
+}
+
+// NewMultiWriter returns a writer of every result to all the outputs.
+//
+// scrapemate hands each result to one of its writers, this writer is the
+// only one given to scrapemate and fans the results out. An output which
+// fails is logged and skipped from then on, the others keep writing; the
+// failures are returned once all the results have been written.
+func NewMultiWriter(outputs ...Output) scrapemate.ResultWriter {
+	return &multiWriter{outputs: outputs}
+}
+
+type multiWriter struct {
+	outputs []Output
+}
+
+func (m *multiWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	log := scrapemate.GetLoggerFromContext(ctx)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	chans := make([]chan scrapemate.Result, len(m.outputs))
+
+	for i, o := range m.outputs {
+		ch := make(chan scrapemate.Result, bufferSize)
+		chans[i] = ch
+
+		wg.Add(1)
+
+		go func(o Output) {
+			defer wg.Done()
+
+			if err := o.Writer.Run(ctx, ch); err != nil {
+				log.Error("output failed", "output", o.Name, "error", err)
+
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", o.Name, err))
+				mu.Unlock()
+			}
+
+			// the results sent to an output which failed are dropped, so
+			// that it does not hold up the others
+			for range ch { //nolint:revive // draining
+			}
+		}(o)
+	}
+
+	for result := range in {
+		for _, ch := range chans {
+			ch <- result
+		}
+	}
+
+	for _, ch := range chans {
+		close(ch)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,62 @@
+package multiwriter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+	"github.com/wojciechkapala/google-maps-scraper/multiwriter"
+)
+
+// collector keeps the titles of the entries it is given.
+type collector struct {
+	titles []string
+}
+
+func (c *collector) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		c.titles = append(c.titles, result.Data.(*gmaps.Entry).Title)
+	}
+
+	return nil
+}
+
+// failing fails on its first result.
+type failing struct{}
+
+func (failing) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	<-in
+
+	return errors.New("disk full")
+}
+
+func Test_MultiWriter(t *testing.T) {
+	const n = 500
+
+	in := make(chan scrapemate.Result)
+
+	go func() {
+		for i := 0; i < n; i++ {
+			in <- scrapemate.Result{Data: &gmaps.Entry{Title: "Pompy"}}
+		}
+
+		close(in)
+	}()
+
+	csv, ndjson := &collector{}, &collector{}
+
+	err := multiwriter.NewMultiWriter(
+		multiwriter.Output{Name: "csv:wyniki.csv", Writer: csv},
+		multiwriter.Output{Name: "xlsx:wyniki.xlsx", Writer: failing{}},
+		multiwriter.Output{Name: "ndjson:wyniki.ndjson", Writer: ndjson},
+	).Run(context.Background(), in)
+
+	// the failure is reported, every other output got every result
+	require.EqualError(t, err, "xlsx:wyniki.xlsx: disk full")
+	require.Len(t, csv.titles, n)
+	require.Len(t, ndjson.titles, n)
+}
@@ -0,0 +1,252 @@
+package parquetwriter
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/gosom/scrapemate"
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+// DefaultRowGroupSize is the number of rows of a row group.
+const DefaultRowGroupSize = 1000
+
+// Row is the schema of the Parquet file, derived from gmaps.Entry. Columns
+// are only ever added to it, so that the files of older crawls can be read
+// together. The registry records, the VAT status, the website health and
+// the relevance are nested, null when they are unknown.
+type Row struct {
+	Query        string            `parquet:"query"`
+	InputID      string            `parquet:"input_id"`
+	Link         string            `parquet:"link"`
+	Title        string            `parquet:"title"`
+	Street       string            `parquet:"street"`
+	Number       string            `parquet:"number"`
+	City         string            `parquet:"city"`
+	Latitude     *float64          `parquet:"latitude,optional"`
+	Longitude    *float64          `parquet:"longitude,optional"`
+	Website      string            `parquet:"website"`
+	Phone        string            `parquet:"phone"`
+	Emails       []string          `parquet:"emails,list"`
+	SocialLinks  map[string]string `parquet:"social_links"`
+	NIP          string            `parquet:"nip"`
+	REGON        string            `parquet:"regon"`
+	KRS          string            `parquet:"krs"`
+	CEIDG        *Registration     `parquet:"ceidg,optional"`
+	GUS          *Registration     `parquet:"gus,optional"`
+	KRSRegistry  *Registration     `parquet:"krs_registry,optional"`
+	VAT          *VAT              `parquet:"vat,optional"`
+	OpeningHours []string          `parquet:"opening_hours,list"`
+	WebsiteInfo  *Website          `parquet:"website_health,optional"`
+	ContactForms []ContactForm     `parquet:"contact_forms,list"`
+	WhatsApp     string            `parquet:"whatsapp"`
+	Messenger    string            `parquet:"messenger"`
+	Telegram     string            `parquet:"telegram"`
+	ChatWidgets  []string          `parquet:"chat_widgets,list"`
+	Relevance    *Relevance        `parquet:"relevance,optional"`
+}
+
+// Registration is a registry record of the business.
+type Registration struct {
+	Name           string   `parquet:"name"`
+	LegalForm      string   `parquet:"legal_form"`
+	OwnerFirstName string   `parquet:"owner_first_name"`
+	OwnerLastName  string   `parquet:"owner_last_name"`
+	NIP            string   `parquet:"nip"`
+	REGON          string   `parquet:"regon"`
+	KRS            string   `parquet:"krs"`
+	Address        string   `parquet:"address"`
+	PostalCode     string   `parquet:"postal_code"`
+	City           string   `parquet:"city"`
+	Voivodeship    string   `parquet:"voivodeship"`
+	StartDate      string   `parquet:"start_date"`
+	Status         string   `parquet:"status"`
+	Link           string   `parquet:"link"`
+	MatchScore     float64  `parquet:"match_score"`
+	LowConfidence  bool     `parquet:"low_confidence"`
+	MatchedOn      []string `parquet:"matched_on,list"`
+}
+
+// VAT is the VIES check of the NIP.
+type VAT struct {
+	Valid bool   `parquet:"valid"`
+	Name  string `parquet:"name"`
+}
+
+// Website is the health of the website of the business.
+type Website struct {
+	StatusCode     int32    `parquet:"status_code"`
+	Error          string   `parquet:"error"`
+	FinalURL       string   `parquet:"final_url"`
+	HTTPS          bool     `parquet:"https"`
+	TLSValid       bool     `parquet:"tls_valid"`
+	ResponseTimeMs int64    `parquet:"response_time_ms"`
+	CMS            string   `parquet:"cms"`
+	Viewport       bool     `parquet:"viewport"`
+	LastModified   string   `parquet:"last_modified"`
+	Redirects      []string `parquet:"redirects,list"`
+	TLSError       string   `parquet:"tls_error"`
+	CMSVersion     string   `parquet:"cms_version"`
+	Generator      string   `parquet:"generator"`
+	Rendered       bool     `parquet:"rendered"`
+}
+
+// ContactForm is a contact form found on the website.
+type ContactForm struct {
+	PageURL string   `parquet:"page_url"`
+	Action  string   `parquet:"action"`
+	Method  string   `parquet:"method"`
+	Fields  []string `parquet:"fields,list"`
+}
+
+// Relevance is the score of the website against the terms of the crawl.
+type Relevance struct {
+	Language     string   `parquet:"language"`
+	Score        float64  `parquet:"score"`
+	MatchedTerms []string `parquet:"matched_terms,list"`
+}
+
+// NewParquetWriter returns a writer of a Parquet file of the entries. The
+// rows are flushed to w as a row group every rowGroupSize rows, so that a
+// long crawl is not kept in memory; the file is complete once its footer
+// is written at the end of the run. A rowGroupSize of zero or less uses
+// DefaultRowGroupSize.
+func NewParquetWriter(w io.Writer, rowGroupSize int) scrapemate.ResultWriter {
+	if rowGroupSize <= 0 {
+		rowGroupSize = DefaultRowGroupSize
+	}
+
+	return &parquetWriter{w: w, rowGroupSize: rowGroupSize}
+}
+
+type parquetWriter struct {
+	w            io.Writer
+	rowGroupSize int
+}
+
+func (p *parquetWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	pw := parquet.NewGenericWriter[Row](p.w, parquet.Compression(&parquet.Snappy))
+
+	buffered := 0
+
+	for result := range in {
+		entry, ok := result.Data.(*gmaps.Entry)
+		if !ok {
+			return errors.New("invalid data type")
+		}
+
+		if _, err := pw.Write([]Row{NewRow(entry)}); err != nil {
+			return err
+		}
+
+		buffered++
+
+		if buffered < p.rowGroupSize {
+			continue
+		}
+
+		if err := pw.Flush(); err != nil {
+			return err
+		}
+
+		buffered = 0
+	}
+
+	return pw.Close()
+}
+
+// NewRow returns the row of the entry.
+func NewRow(e *gmaps.Entry) Row {
+	row := Row{
+		Query:        e.Query,
+		InputID:      e.ID,
+		Link:         e.Link,
+		Title:        e.Title,
+		Street:       e.Address.Street,
+		Number:       e.Address.Number,
+		City:         e.City,
+		Website:      e.WebSite,
+		Phone:        e.Phone,
+		Emails:       e.Emails,
+		SocialLinks:  e.SocialLinks,
+		NIP:          e.NIP,
+		REGON:        e.REGON,
+		KRS:          e.KRS,
+		CEIDG:        newRegistration(e.CEIDG),
+		GUS:          newRegistration(e.GUS),
+		KRSRegistry:  newRegistration(e.KRSRegistry),
+		OpeningHours: e.OpeningHours,
+	}
+
+	if e.HasCoordinates() {
+		lat, lng := e.Latitude, e.Longitude
+		row.Latitude, row.Longitude = &lat, &lng
+	}
+
+	if e.VAT != nil {
+		row.VAT = &VAT{Valid: e.VAT.Valid, Name: e.VAT.Name}
+	}
+
+	if h := e.Website; h != nil {
+		row.WebsiteInfo = &Website{
+			StatusCode:     int32(h.StatusCode),
+			Error:          h.Error,
+			FinalURL:       h.FinalURL,
+			HTTPS:          h.HTTPS,
+			TLSValid:       h.TLSValid,
+			ResponseTimeMs: h.ResponseTime,
+			CMS:            h.CMS,
+			Viewport:       h.Viewport,
+			LastModified:   h.LastModified,
+			Redirects:      h.Redirects,
+			TLSError:       h.TLSError,
+			CMSVersion:     h.CMSVersion,
+			Generator:      h.Generator,
+			Rendered:       h.Rendered,
+		}
+	}
+
+	if o := e.Outreach; o != nil {
+		for _, f := range o.ContactForms {
+			row.ContactForms = append(row.ContactForms, ContactForm{PageURL: f.URL, Action: f.Action, Method: f.Method, Fields: f.Fields})
+		}
+
+		row.WhatsApp, row.Messenger, row.Telegram = o.WhatsApp, o.Messenger, o.Telegram
+		row.ChatWidgets = o.ChatWidgets
+	}
+
+	if r := e.Relevance; r != nil {
+		row.Relevance = &Relevance{Language: r.Language, Score: r.Score, MatchedTerms: r.Matched}
+	}
+
+	return row
+}
+
+func newRegistration(r *gmaps.CompanyRegistration) *Registration {
+	if r == nil {
+		return nil
+	}
+
+	return &Registration{
+		Name:           r.Name,
+		LegalForm:      r.LegalForm,
+		OwnerFirstName: r.OwnerFirstName,
+		OwnerLastName:  r.OwnerLastName,
+		NIP:            r.NIP,
+		REGON:          r.REGON,
+		KRS:            r.KRS,
+		Address:        r.Address.String(),
+		PostalCode:     r.Address.PostalCode,
+		City:           r.Address.City,
+		Voivodeship:    r.Address.Voivodeship,
+		StartDate:      r.StartDate,
+		Status:         r.Status,
+		Link:           r.Link,
+		MatchScore:     r.MatchScore,
+		LowConfidence:  r.LowConfidence,
+		MatchedOn:      r.MatchedOn,
+	}
+}
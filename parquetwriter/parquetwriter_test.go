@@ -0,0 +1,109 @@
+package parquetwriter_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+	"github.com/wojciechkapala/google-maps-scraper/parquetwriter"
+)
+
+func Test_ParquetWriter(t *testing.T) {
+	entries := []*gmaps.Entry{
+		{
+			Query:       "pompy ciepła Bytom",
+			Title:       "Pompy Kowalski",
+			City:        "Bytom",
+			Latitude:    50.348,
+			Longitude:   18.915,
+			Emails:      []string{"biuro@pompy.pl", "serwis@pompy.pl"},
+			SocialLinks: map[string]string{"facebook": "https://facebook.com/pompy"},
+			NIP:         "6262626262",
+			CEIDG:       &gmaps.CompanyRegistration{Name: "Jan Kowalski Pompy", OwnerFirstName: "Jan", OwnerLastName: "Kowalski", MatchScore: 0.9},
+			Website: &gmaps.WebsiteHealth{
+				StatusCode: 200,
+				HTTPS:      true,
+				Redirects:  []string{"http://pompy.pl/"},
+				TLSError:   "certificate has expired",
+				CMS:        "WordPress",
+				CMSVersion: "6.4.2",
+				Generator:  "WordPress 6.4.2",
+				Rendered:   true,
+			},
+			Relevance: &gmaps.Relevance{Language: "pl", Score: 0.75, Matched: []string{"pompy"}},
+			Outreach: &gmaps.Outreach{ContactForms: []gmaps.ContactForm{
+				{URL: "https://pompy.pl/kontakt", Action: "https://pompy.pl/wyslij", Method: "POST", Fields: []string{"imie", "email", "wiadomosc"}},
+				{URL: "https://pompy.pl/kontakt", Action: "https://docs.google.com/forms/d/e/abc/viewform"},
+			}},
+		},
+		{Query: "pompy ciepła Bytom", Title: "Biuro Nowak", SocialLinks: map[string]string{}},
+		{Query: "pompy ciepła Gliwice", Title: "Sklep AGD"},
+	}
+
+	in := make(chan scrapemate.Result, len(entries))
+	for _, e := range entries {
+		in <- scrapemate.Result{Data: e}
+	}
+
+	close(in)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, parquetwriter.NewParquetWriter(&buf, 2).Run(context.Background(), in))
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), f.NumRows())
+	// a row group every two rows
+	require.Len(t, f.RowGroups(), 2)
+
+	rows := make([]parquetwriter.Row, 3)
+
+	n, err := parquet.NewGenericReader[parquetwriter.Row](f).Read(rows)
+	if err != io.EOF {
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 3, n)
+
+	kowalski := rows[0]
+	require.Equal(t, "Pompy Kowalski", kowalski.Title)
+	require.Equal(t, []string{"biuro@pompy.pl", "serwis@pompy.pl"}, kowalski.Emails)
+	require.Equal(t, "https://facebook.com/pompy", kowalski.SocialLinks["facebook"])
+	require.NotNil(t, kowalski.Latitude)
+	require.Equal(t, 50.348, *kowalski.Latitude)
+	require.NotNil(t, kowalski.CEIDG)
+	require.Equal(t, "Kowalski", kowalski.CEIDG.OwnerLastName)
+	require.Nil(t, kowalski.GUS)
+	require.Equal(t, parquetwriter.Website{
+		StatusCode: 200,
+		HTTPS:      true,
+		CMS:        "WordPress",
+		Redirects:  []string{"http://pompy.pl/"},
+		TLSError:   "certificate has expired",
+		CMSVersion: "6.4.2",
+		Generator:  "WordPress 6.4.2",
+		Rendered:   true,
+	}, *kowalski.WebsiteInfo)
+	require.Equal(t, 0.75, kowalski.Relevance.Score)
+	require.Equal(t, []parquetwriter.ContactForm{
+		{PageURL: "https://pompy.pl/kontakt", Action: "https://pompy.pl/wyslij", Method: "POST", Fields: []string{"imie", "email", "wiadomosc"}},
+		{PageURL: "https://pompy.pl/kontakt", Action: "https://docs.google.com/forms/d/e/abc/viewform", Fields: []string{}},
+	}, kowalski.ContactForms)
+
+	// unknown values are null
+	nowak := rows[1]
+	require.Nil(t, nowak.Latitude)
+	require.Nil(t, nowak.CEIDG)
+	require.Nil(t, nowak.Relevance)
+	require.Empty(t, nowak.Emails)
+	require.Empty(t, nowak.ContactForms)
+
+	require.Equal(t, "pompy ciepła Gliwice", rows[2].Query)
+}
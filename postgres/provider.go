@@ -10,7 +10,7 @@ import (
 
 	"github.com/gosom/scrapemate"
 
-	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
 )
 
 const (
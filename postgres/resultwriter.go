@@ -8,7 +8,7 @@ import (
 
 	"github.com/gosom/scrapemate"
 
-	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
 )
 
 func NewResultWriter(db *sql.DB) scrapemate.ResultWriter {
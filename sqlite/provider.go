@@ -0,0 +1,276 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/gob"
+	"errors"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+var _ scrapemate.JobProvider = (*provider)(nil)
+
+type provider struct {
+	db         *sql.DB
+	enrichment *gmaps.Enrichment
+}
+
+// NewProvider returns a job provider keeping the jobs in the database. The
+// jobs it hands out get the enrichment services of the run, which are not
+// stored with them.
+func NewProvider(db *sql.DB, enrichment *gmaps.Enrichment) scrapemate.JobProvider {
+	return &provider{db: db, enrichment: enrichment}
+}
+
+type payload struct {
+	payloadType string
+	data        []byte
+}
+
+//nolint:gocritic // it contains about unnamed results
+func (p *provider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan error) {
+	outc := make(chan scrapemate.IJob)
+	errc := make(chan error, 1)
+
+	// SQLite has no SELECT FOR UPDATE, a single UPDATE takes the job
+	q := `
+	UPDATE gmaps_jobs
+	SET status = ?
+	WHERE id = (
+		SELECT id FROM gmaps_jobs
+		WHERE status = ?
+		ORDER BY priority ASC, rowid ASC LIMIT 1
+	)
+	RETURNING payload_type, payload
+	`
+
+	go func() {
+		defer close(outc)
+		defer close(errc)
+
+		const tickEvery = 100 * time.Millisecond
+
+		ticker := time.NewTicker(tickEvery)
+
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			payloads, err := p.next(ctx, q)
+			if err != nil {
+				errc <- err
+
+				return
+			}
+
+			for _, pl := range payloads {
+				job, err := p.decode(pl)
+				if err != nil {
+					errc <- err
+
+					return
+				}
+
+				select {
+				case outc <- job:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return outc, errc
+}
+
+// next takes the next jobs. The rows are read before the jobs are handed
+// out, since the single connection is needed to push their children.
+func (p *provider) next(ctx context.Context, q string) ([]payload, error) {
+	rows, err := p.db.QueryContext(ctx, q, statusQueued, statusNew)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payloads []payload
+
+	for rows.Next() {
+		var pl payload
+
+		if err := rows.Scan(&pl.payloadType, &pl.data); err != nil {
+			return nil, err
+		}
+
+		payloads = append(payloads, pl)
+	}
+
+	return payloads, rows.Err()
+}
+
+func (p *provider) decode(pl payload) (scrapemate.IJob, error) {
+	dec := gob.NewDecoder(bytes.NewReader(pl.data))
+
+	var job scrapemate.IJob
+
+	switch pl.payloadType {
+	case "search":
+		j := new(gmaps.GmapJob)
+
+		if err := dec.Decode(j); err != nil {
+			return nil, err
+		}
+
+		job = j
+	case "place":
+		j := new(gmaps.PlaceJob)
+
+		if err := dec.Decode(j); err != nil {
+			return nil, err
+		}
+
+		job = j
+	case "email":
+		j := new(gmaps.EmailExtractJob)
+
+		if err := dec.Decode(j); err != nil {
+			return nil, err
+		}
+
+		job = j
+	case "registry":
+		j := new(gmaps.RegistryExtractJob)
+
+		if err := dec.Decode(j); err != nil {
+			return nil, err
+		}
+
+		job = j
+	default:
+		return nil, errors.New("invalid payload type")
+	}
+
+	p.enrichment.Attach(job)
+
+	return &trackedJob{IJob: job, p: p}, nil
+}
+
+// Push pushes a job to the job provider. The children of the jobs it
+// hands out are pushed once the job is processed.
+func (p *provider) Push(ctx context.Context, job scrapemate.IJob) error {
+	return p.save(ctx, "", job)
+}
+
+// save pushes the jobs and marks the job doneID as done in one transaction,
+// no job is marked when doneID is empty.
+func (p *provider) save(ctx context.Context, doneID string, jobs ...scrapemate.IJob) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback() //nolint:errcheck // a no-op after the commit
+
+	for _, job := range jobs {
+		if err := insertJob(ctx, tx, job); err != nil {
+			return err
+		}
+	}
+
+	if doneID != "" {
+		if _, err := tx.ExecContext(ctx, `UPDATE gmaps_jobs SET status = ? WHERE id = ?`, statusDone, doneID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func insertJob(ctx context.Context, tx *sql.Tx, job scrapemate.IJob) error {
+	q := `INSERT INTO gmaps_jobs
+		(id, parent_id, priority, payload_type, payload, created_at, status)
+		VALUES
+		(?, ?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`
+
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+
+	var payloadType string
+
+	switch j := job.(type) {
+	case *gmaps.GmapJob:
+		payloadType = "search"
+
+		if err := enc.Encode(j); err != nil {
+			return err
+		}
+	case *gmaps.PlaceJob:
+		payloadType = "place"
+
+		if err := enc.Encode(j); err != nil {
+			return err
+		}
+	case *gmaps.EmailExtractJob:
+		payloadType = "email"
+
+		if err := enc.Encode(j); err != nil {
+			return err
+		}
+	case *gmaps.RegistryExtractJob:
+		payloadType = "registry"
+
+		if err := enc.Encode(j); err != nil {
+			return err
+		}
+	default:
+		return errors.New("invalid job type")
+	}
+
+	_, err := tx.ExecContext(ctx, q,
+		job.GetID(), job.GetParentID(), job.GetPriority(), payloadType, buf.Bytes(), time.Now().UTC(), statusNew,
+	)
+
+	return err
+}
+
+// trackedJob is a job handed out by the provider. Once it is processed, its
+// children are pushed and, unless it has a result to write, it is marked
+// as done in one transaction, so that a resumed run neither loses the
+// children nor processes the job again.
+type trackedJob struct {
+	scrapemate.IJob
+	p *provider
+}
+
+// Unwrap returns the job read from the database.
+func (j *trackedJob) Unwrap() scrapemate.IJob {
+	return j.IJob
+}
+
+func (j *trackedJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+	result, next, err := j.IJob.Process(ctx, resp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// a job with a result is done once the result writer saves it
+	doneID := j.GetID()
+	if result != nil && j.UseInResults() {
+		doneID = ""
+	}
+
+	if err := j.p.save(ctx, doneID, next...); err != nil {
+		return nil, nil, err
+	}
+
+	return result, nil, nil
+}
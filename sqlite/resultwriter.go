@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+func NewResultWriter(db *sql.DB) scrapemate.ResultWriter {
+	return &resultWriter{db: db}
+}
+
+type resultWriter struct {
+	db *sql.DB
+}
+
+func (r *resultWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		entry, ok := result.Data.(*gmaps.Entry)
+
+		if !ok {
+			return errors.New("invalid data type")
+		}
+
+		if err := r.saveEntry(ctx, result.Job, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveEntry saves the entry and marks the job which produced it as done at
+// once, so that a resumed run neither loses nor repeats it. A place seen
+// before is kept as it was first saved.
+func (r *resultWriter) saveEntry(ctx context.Context, job scrapemate.IJob, entry *gmaps.Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback() //nolint:errcheck // a no-op after the commit
+
+	q := `INSERT INTO results
+		(link, data, created_at)
+		VALUES
+		(NULLIF(?, ''), ?, ?) ON CONFLICT DO NOTHING
+		`
+
+	if _, err := tx.ExecContext(ctx, q, entry.Link, data, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	if job != nil {
+		if _, err := tx.ExecContext(ctx, `UPDATE gmaps_jobs SET status = ? WHERE id = ?`, statusDone, job.GetID()); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
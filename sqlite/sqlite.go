@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	// the pure Go driver, so that the binary builds without cgo
+	_ "modernc.org/sqlite"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+const (
+	statusNew    = "new"
+	statusQueued = "queued"
+	statusDone   = "done"
+)
+
+// schema mirrors the postgres migrations. The places view has the main
+// fields of the results as columns, for querying them with SQL.
+const schema = `
+CREATE TABLE IF NOT EXISTS gmaps_jobs(
+	id TEXT PRIMARY KEY,
+	parent_id TEXT NOT NULL,
+	priority INTEGER NOT NULL,
+	payload_type TEXT NOT NULL,
+	payload BLOB NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	status TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS gmaps_jobs_status ON gmaps_jobs(status, priority);
+
+CREATE TABLE IF NOT EXISTS results(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	link TEXT UNIQUE,
+	data TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE VIEW IF NOT EXISTS places AS
+	SELECT
+		id,
+		json_extract(data, '$.query') AS query,
+		json_extract(data, '$.title') AS title,
+		json_extract(data, '$.city') AS city,
+		json_extract(data, '$.phone') AS phone,
+		json_extract(data, '$.web_site') AS website,
+		json_extract(data, '$.emails') AS emails,
+		json_extract(data, '$.nip') AS nip,
+		json_extract(data, '$.regon') AS regon,
+		json_extract(data, '$.krs') AS krs,
+		json_extract(data, '$.latitude') AS latitude,
+		json_extract(data, '$.longitude') AS longitude,
+		json_extract(data, '$.relevance.score') AS relevance_score,
+		link,
+		created_at
+	FROM results;
+`
+
+// Open opens the database at path, creating it and its tables when needed.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite has a single writer, the workers wait for their turn instead
+	// of failing with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Requeue queues again the jobs handed out by a previous run and not
+// finished, because it was stopped, so that a new run resumes them. It
+// returns the number of jobs left to do.
+func Requeue(ctx context.Context, db *sql.DB) (int, error) {
+	if _, err := db.ExecContext(ctx, `UPDATE gmaps_jobs SET status = ? WHERE status = ?`, statusNew, statusQueued); err != nil {
+		return 0, err
+	}
+
+	return PendingJobs(ctx, db)
+}
+
+// PendingJobs returns the number of jobs left to do.
+func PendingJobs(ctx context.Context, db *sql.DB) (int, error) {
+	var n int
+
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM gmaps_jobs WHERE status != ?`, statusDone).Scan(&n)
+
+	return n, err
+}
+
+// ReadEntries reads the results in the order they were written.
+func ReadEntries(ctx context.Context, db *sql.DB) ([]*gmaps.Entry, error) {
+	rows, err := db.QueryContext(ctx, `SELECT data FROM results ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*gmaps.Entry
+
+	for rows.Next() {
+		var data []byte
+
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		var e gmaps.Entry
+
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
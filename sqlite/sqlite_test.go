@@ -0,0 +1,218 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+	"github.com/wojciechkapala/google-maps-scraper/sqlite"
+)
+
+func nextJob(t *testing.T, ctx context.Context, p scrapemate.JobProvider) scrapemate.IJob {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	jobc, errc := p.Jobs(ctx)
+
+	select {
+	case job := <-jobc:
+		return job
+	case err := <-errc:
+		require.NoError(t, err)
+	case <-ctx.Done():
+		require.Fail(t, "no job")
+	}
+
+	return nil
+}
+
+// unwrap returns the job read from the database, which the provider hands
+// out wrapped.
+func unwrap(t *testing.T, job scrapemate.IJob) scrapemate.IJob {
+	t.Helper()
+
+	w, ok := job.(interface{ Unwrap() scrapemate.IJob })
+	require.True(t, ok)
+
+	return w.Unwrap()
+}
+
+func newResponse(t *testing.T, html string) *scrapemate.Response {
+	t.Helper()
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	require.NoError(t, err)
+
+	return &scrapemate.Response{Body: []byte(html), Document: doc}
+}
+
+func Test_ProviderResume(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "run.db")
+
+	db, err := sqlite.Open(ctx, path)
+	require.NoError(t, err)
+
+	p := sqlite.NewProvider(db, nil)
+
+	seed := gmaps.NewGmapJob("seed", "pl", "pompy ciepła Bytom", 5, true, gmaps.WithRelevance(gmaps.RelevanceConfig{MinScore: 0.3}))
+	require.NoError(t, p.Push(ctx, seed))
+	// pushing a seed again, as a resumed run does, is a no-op
+	require.NoError(t, p.Push(ctx, seed))
+
+	pending, err := sqlite.PendingJobs(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, 1, pending)
+
+	// the settings of the job are kept
+	job, ok := unwrap(t, nextJob(t, ctx, p)).(*gmaps.GmapJob)
+	require.True(t, ok)
+	require.Equal(t, "pompy ciepła Bytom", job.Query)
+	require.Equal(t, 5, job.MaxDepth)
+	require.Equal(t, 0.3, job.Relevance.MinScore)
+	require.Equal(t, []string{"pompy", "ciepła", "bytom"}, job.Relevance.Terms)
+
+	// the run stops before the job finishes, it is queued again
+	require.NoError(t, db.Close())
+
+	db, err = sqlite.Open(ctx, path)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	pending, err = sqlite.Requeue(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, 1, pending)
+
+	p = sqlite.NewProvider(db, &gmaps.Enrichment{})
+	seedJob := nextJob(t, ctx, p)
+	require.Equal(t, "seed", seedJob.GetID())
+
+	// processing the job pushes its children and finishes it at once
+	_, next, err := seedJob.Process(ctx, newResponse(t, `<div role="feed">
+		<div jsaction="a"><a href="https://www.google.com/maps/place/x"></a></div>
+		<div jsaction="b"><a href="https://www.google.com/maps/place/y"></a></div>
+	</div>`))
+	require.NoError(t, err)
+	require.Empty(t, next)
+
+	pending, err = sqlite.PendingJobs(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, 2, pending)
+
+	got, ok := unwrap(t, nextJob(t, ctx, p)).(*gmaps.PlaceJob)
+	require.True(t, ok)
+	require.Equal(t, "pompy ciepła Bytom", got.Query)
+
+	// writing the result finishes the place, a place seen before is kept once
+	entry := &gmaps.Entry{Query: got.Query, Title: "Pompy Kowalski", Link: got.URL, NIP: "6262626262", Emails: []string{"biuro@pompy.pl"}}
+
+	in := make(chan scrapemate.Result, 2)
+	in <- scrapemate.Result{Job: got, Data: entry}
+	in <- scrapemate.Result{Job: got, Data: entry}
+
+	close(in)
+
+	require.NoError(t, sqlite.NewResultWriter(db).Run(ctx, in))
+
+	pending, err = sqlite.PendingJobs(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, 1, pending)
+
+	entries, err := sqlite.ReadEntries(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "Pompy Kowalski", entries[0].Title)
+
+	var title, nip string
+
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT title, nip FROM places WHERE query = ?`, "pompy ciepła Bytom").Scan(&title, &nip))
+	require.Equal(t, "Pompy Kowalski", title)
+	require.Equal(t, "6262626262", nip)
+}
+
+func Test_ProviderEmailJob(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "run.db"))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	p := sqlite.NewProvider(db, nil)
+
+	entry := &gmaps.Entry{Title: "Pompy Kowalski", WebSite: "https://pompy.pl", SocialLinks: map[string]string{}}
+	relevance := gmaps.WithEmailJobRelevance(gmaps.RelevanceConfig{Terms: []string{"pompy", "ciepła"}, MinScore: 0.5})
+	require.NoError(t, p.Push(ctx, gmaps.NewEmailJob("place", entry, relevance)))
+	require.NoError(t, p.Push(ctx, gmaps.NewEmailJob("place", entry, relevance)))
+
+	pending, err := sqlite.PendingJobs(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, 2, pending)
+
+	handed := nextJob(t, ctx, p)
+
+	job, ok := unwrap(t, handed).(*gmaps.EmailExtractJob)
+	require.True(t, ok)
+	require.Equal(t, "https://pompy.pl", job.Entry.WebSite)
+
+	// an irrelevant website has neither a result nor children, the job is
+	// done once processed
+	result, next, err := handed.Process(ctx, newResponse(t, `<html><body><h1>Piekarnia</h1></body></html>`))
+	require.NoError(t, err)
+	require.Nil(t, result)
+	require.Empty(t, next)
+
+	pending, err = sqlite.PendingJobs(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, 1, pending)
+}
+
+type vies struct{}
+
+func (vies) CheckVAT(_ context.Context, countryCode, vatNumber string) (*gmaps.VATStatus, error) {
+	return &gmaps.VATStatus{CountryCode: countryCode, VATNumber: vatNumber, Valid: true}, nil
+}
+
+func Test_ProviderRegistryJob(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "run.db"))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	p := sqlite.NewProvider(db, &gmaps.Enrichment{VIES: vies{}})
+
+	entry := &gmaps.Entry{Title: "Pompy Kowalski", NIP: "6262626262", SocialLinks: map[string]string{}}
+	require.NoError(t, p.Push(ctx, gmaps.NewRegistryJob("email", entry, nil)))
+	// pushed again after a resume
+	require.NoError(t, p.Push(ctx, gmaps.NewRegistryJob("email", entry, nil)))
+
+	pending, err := sqlite.PendingJobs(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, 1, pending)
+
+	job, ok := unwrap(t, nextJob(t, ctx, p)).(*gmaps.RegistryExtractJob)
+	require.True(t, ok)
+	require.Equal(t, "email", job.GetParentID())
+
+	// the enricher chain of the run is attached to the job read back
+	data, _, err := job.Process(ctx, nil)
+	require.NoError(t, err)
+
+	result, ok := data.(*gmaps.Entry)
+	require.True(t, ok)
+	require.Equal(t, "6262626262", result.NIP)
+	require.NotNil(t, result.VAT)
+	require.True(t, result.VAT.Valid)
+}
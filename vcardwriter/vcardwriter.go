@@ -0,0 +1,170 @@
+package vcardwriter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+// maxLineOctets is the length a content line is folded at (RFC 6350 3.2).
+const maxLineOctets = 75
+
+// socialNetworks are written as X-SOCIALPROFILE, which phone address books
+// show as profile links.
+var socialNetworks = []string{"facebook", "instagram", "twitter"}
+
+// textEscaper escapes a text value (RFC 6350 3.4).
+var textEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// NewVCardWriter returns a writer of a .vcf file with a vCard 3.0 for every
+// entry, which phones and address books import as company contacts. The
+// file is UTF-8 and every card is flushed as it is written.
+func NewVCardWriter(w io.Writer) scrapemate.ResultWriter {
+	return &vcardWriter{w: bufio.NewWriter(w)}
+}
+
+type vcardWriter struct {
+	w *bufio.Writer
+}
+
+func (v *vcardWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		entry, ok := result.Data.(*gmaps.Entry)
+		if !ok {
+			return errors.New("invalid data type")
+		}
+
+		if err := WriteVCard(v.w, entry); err != nil {
+			return err
+		}
+
+		if err := v.w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteVCards writes a vCard for every entry.
+func WriteVCards(w io.Writer, entries []*gmaps.Entry) error {
+	for _, e := range entries {
+		if err := WriteVCard(w, e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteVCard writes the vCard of the entry.
+func WriteVCard(w io.Writer, e *gmaps.Entry) error {
+	card := &card{}
+
+	card.line("BEGIN", "VCARD")
+	card.line("VERSION", "3.0")
+	card.line("FN", escape(e.Title))
+	card.line("N", ";;;;")
+	card.line("ORG", escape(e.Title))
+	card.line("X-ABShowAs", "COMPANY")
+
+	if e.Query != "" {
+		card.line("CATEGORIES", escape(e.Query))
+	}
+
+	if e.Phone != "" {
+		card.line("TEL;TYPE=WORK,VOICE", escape(e.Phone))
+	}
+
+	for _, email := range e.Emails {
+		card.line("EMAIL;TYPE=INTERNET,WORK", escape(email))
+	}
+
+	if e.WebSite != "" {
+		card.line("URL", escape(e.WebSite))
+	}
+
+	if street := strings.TrimSpace(e.Address.Street + " " + e.Address.Number); street != "" || e.City != "" {
+		// post office box;extended address;street;locality;region;postal code;country
+		card.line("ADR;TYPE=WORK", strings.Join([]string{"", "", escape(street), escape(e.City), "", "", ""}, ";"))
+	}
+
+	for _, network := range socialNetworks {
+		if link := e.SocialLinks[network]; link != "" {
+			card.line("X-SOCIALPROFILE;TYPE="+network, escape(link))
+		}
+	}
+
+	if e.HasCoordinates() {
+		card.line("GEO", strconv.FormatFloat(e.Latitude, 'f', 7, 64)+";"+strconv.FormatFloat(e.Longitude, 'f', 7, 64))
+	}
+
+	if note := note(e); note != "" {
+		card.line("NOTE", escape(note))
+	}
+
+	card.line("END", "VCARD")
+
+	_, err := io.WriteString(w, card.String())
+
+	return err
+}
+
+// note carries the registry numbers and the Google Maps link, which have
+// no vCard property.
+func note(e *gmaps.Entry) string {
+	var lines []string
+
+	for _, id := range []struct{ name, value string }{
+		{"NIP", e.NIP},
+		{"REGON", e.REGON},
+		{"KRS", e.KRS},
+		{"Google Maps", e.Link},
+	} {
+		if id.value != "" {
+			lines = append(lines, id.name+": "+id.value)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func escape(s string) string {
+	return textEscaper.Replace(s)
+}
+
+type card struct {
+	strings.Builder
+}
+
+// line writes a content line folded at 75 octets, never inside a UTF-8
+// sequence, with CRLF line breaks.
+func (c *card) line(name, value string) {
+	line := name + ":" + value
+	limit := maxLineOctets
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		c.WriteString(line[:cut])
+		c.WriteString("\r\n ")
+
+		line = line[cut:]
+		// the leading space of a continuation line counts
+		limit = maxLineOctets - 1
+	}
+
+	c.WriteString(line)
+	c.WriteString("\r\n")
+}
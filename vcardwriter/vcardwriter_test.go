@@ -0,0 +1,86 @@
+package vcardwriter_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+	"github.com/wojciechkapala/google-maps-scraper/vcardwriter"
+)
+
+func Test_VCardWriter(t *testing.T) {
+	in := make(chan scrapemate.Result, 2)
+	in <- scrapemate.Result{Data: &gmaps.Entry{
+		Query:   "pompy ciepła Bytom",
+		Title:   "Pompy; Kowalski, Syn",
+		Phone:   "601 234 567",
+		Emails:  []string{"biuro@pompy.pl", "serwis@pompy.pl"},
+		WebSite: "https://pompy.pl",
+		Address: gmaps.Address{Street: "Żeromskiego", Number: "12"},
+		City:    "Bytom",
+		SocialLinks: map[string]string{
+			"facebook": "https://facebook.com/pompy",
+		},
+		NIP:       "6262626262",
+		Latitude:  50.348,
+		Longitude: 18.915,
+	}}
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "Biuro Nowak", SocialLinks: map[string]string{}}}
+
+	close(in)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, vcardwriter.NewVCardWriter(&buf).Run(context.Background(), in))
+
+	out := buf.String()
+	require.Equal(t, 2, strings.Count(out, "BEGIN:VCARD\r\n"))
+	require.Equal(t, 2, strings.Count(out, "END:VCARD\r\n"))
+
+	require.Contains(t, out, "FN:Pompy\\; Kowalski\\, Syn\r\n")
+	require.Contains(t, out, "ORG:Pompy\\; Kowalski\\, Syn\r\n")
+	require.Contains(t, out, "CATEGORIES:pompy ciepła Bytom\r\n")
+	require.Contains(t, out, "TEL;TYPE=WORK,VOICE:601 234 567\r\n")
+	require.Contains(t, out, "EMAIL;TYPE=INTERNET,WORK:serwis@pompy.pl\r\n")
+	require.Contains(t, out, "URL:https://pompy.pl\r\n")
+	require.Contains(t, out, "ADR;TYPE=WORK:;;Żeromskiego 12;Bytom;;;\r\n")
+	require.Contains(t, out, "X-SOCIALPROFILE;TYPE=facebook:https://facebook.com/pompy\r\n")
+	require.Contains(t, out, "GEO:50.3480000;18.9150000\r\n")
+	require.Contains(t, out, "NOTE:NIP: 6262626262\r\n")
+
+	// the second card has only what is known
+	second := out[strings.LastIndex(out, "BEGIN:VCARD"):]
+	require.NotContains(t, second, "TEL")
+	require.NotContains(t, second, "ADR")
+	require.NotContains(t, second, "NOTE")
+}
+
+func Test_WriteVCardFolding(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := vcardwriter.WriteVCard(&buf, &gmaps.Entry{
+		Title:       strings.Repeat("Zakład Usług Ślusarskich ", 6),
+		NIP:         "6262626262",
+		REGON:       "123456785",
+		Link:        "https://www.google.com/maps/place/x",
+		SocialLinks: map[string]string{},
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	for _, line := range lines {
+		require.LessOrEqual(t, len(line), 75)
+		require.True(t, utf8.ValidString(line), line)
+	}
+
+	// unfolding gives the values back
+	unfolded := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	require.Contains(t, unfolded, "FN:"+strings.Repeat("Zakład Usług Ślusarskich ", 6)+"\r\n")
+	require.Contains(t, unfolded, "NOTE:NIP: 6262626262\\nREGON: 123456785\\nGoogle Maps: https://www.google.com/maps/place/x\r\n")
+}
@@ -0,0 +1,11 @@
+package xlsxwriter
+
+import "testing"
+
+// SetMaxSheetLinks sets the hyperlink limit of a sheet for the test t.
+func SetMaxSheetLinks(t *testing.T, n int) {
+	prev := maxSheetLinks
+	maxSheetLinks = n
+
+	t.Cleanup(func() { maxSheetLinks = prev })
+}
@@ -0,0 +1,370 @@
+package xlsxwriter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gosom/scrapemate"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+)
+
+// SheetBy tells how the entries are split into sheets.
+type SheetBy string
+
+const (
+	SheetByQuery SheetBy = "query"
+	SheetByCity  SheetBy = "city"
+)
+
+const (
+	defaultSheet = "Results"
+	maxSheetName = 31
+	maxColWidth  = 60
+	minColWidth  = 8
+	queryColumn  = "query"
+)
+
+var (
+	// boolColumns and numberColumns are written as typed cells, so that
+	// they can be filtered and sorted in Excel.
+	boolColumns = map[string]bool{
+		"ceidg_low_confidence": true,
+		"vat_valid":            true,
+		"website_https":        true,
+		"website_tls_valid":    true,
+		"website_viewport":     true,
+	}
+	numberColumns = map[string]bool{
+		"ceidg_match_score":   true,
+		"website_status":      true,
+		"website_response_ms": true,
+		"relevance_score":     true,
+		"latitude":            true,
+		"longitude":           true,
+	}
+	linkColumns = map[string]bool{
+		"website":      true,
+		"facebook":     true,
+		"instagram":    true,
+		"twitter":      true,
+		"ceidg_link":   true,
+		"contact_form": true,
+		"whatsapp":     true,
+		"messenger":    true,
+		"telegram":     true,
+	}
+	// maxSheetLinks is the most hyperlinks excelize allows in a worksheet,
+	// the links past it are written as plain strings.
+	maxSheetLinks     = 65530
+	sheetNameReplacer = strings.NewReplacer("[", " ", "]", " ", ":", " ", "*", " ", "?", " ", "/", " ", "\\", " ")
+)
+
+// ParseSheetBy returns the sheet grouping named s, by query when s is empty.
+func ParseSheetBy(s string) (SheetBy, error) {
+	switch SheetBy(s) {
+	case "", SheetByQuery:
+		return SheetByQuery, nil
+	case SheetByCity:
+		return SheetByCity, nil
+	default:
+		return "", fmt.Errorf("unknown sheet grouping %q, use %q or %q", s, SheetByQuery, SheetByCity)
+	}
+}
+
+// NewXLSXWriter returns a writer of an Excel workbook with a sheet per
+// query or per city. The columns are the CSV columns, with booleans and
+// numbers as typed cells and links as hyperlinks. The workbook is written
+// to w once all the results have been received.
+func NewXLSXWriter(w io.Writer, sheetBy SheetBy) scrapemate.ResultWriter {
+	return &xlsxWriter{w: w, sheetBy: sheetBy}
+}
+
+type xlsxWriter struct {
+	w       io.Writer
+	sheetBy SheetBy
+}
+
+type sheet struct {
+	name    string
+	entries []*gmaps.Entry
+}
+
+func (x *xlsxWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	var (
+		sheets []*sheet
+		byKey  = map[string]*sheet{}
+	)
+
+	for result := range in {
+		entry, ok := result.Data.(*gmaps.Entry)
+		if !ok {
+			return errors.New("invalid data type")
+		}
+
+		key := x.sheetKey(entry)
+
+		s, ok := byKey[key]
+		if !ok {
+			s = &sheet{name: key}
+			byKey[key] = s
+			sheets = append(sheets, s)
+		}
+
+		s.entries = append(s.entries, entry)
+	}
+
+	return x.write(sheets)
+}
+
+func (x *xlsxWriter) sheetKey(e *gmaps.Entry) string {
+	if x.sheetBy == SheetByCity {
+		return strings.TrimSpace(e.City)
+	}
+
+	if e.Query != "" {
+		return e.Query
+	}
+
+	return e.ID
+}
+
+func (x *xlsxWriter) write(sheets []*sheet) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if len(sheets) == 0 {
+		sheets = []*sheet{{name: defaultSheet}}
+	}
+
+	styles, err := newStyles(f)
+	if err != nil {
+		return err
+	}
+
+	names := map[string]bool{}
+
+	for i, s := range sheets {
+		name := sheetName(s.name, names)
+
+		if i == 0 {
+			err = f.SetSheetName(f.GetSheetName(0), name)
+		} else {
+			_, err = f.NewSheet(name)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := writeSheet(f, name, s.entries, styles); err != nil {
+			return fmt.Errorf("sheet %q: %w", name, err)
+		}
+	}
+
+	return f.Write(x.w)
+}
+
+type styles struct {
+	header, link, wrap int
+}
+
+func newStyles(f *excelize.File) (styles, error) {
+	var (
+		s   styles
+		err error
+	)
+
+	s.header, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"DDEBF7"}},
+	})
+	if err != nil {
+		return s, err
+	}
+
+	s.link, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Color: "0563C1", Underline: "single"},
+	})
+	if err != nil {
+		return s, err
+	}
+
+	s.wrap, err = f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{WrapText: true, Vertical: "top"},
+	})
+
+	return s, err
+}
+
+func writeSheet(f *excelize.File, name string, entries []*gmaps.Entry, st styles) error {
+	headers := append([]string{queryColumn}, (&gmaps.Entry{}).CsvHeaders()...)
+
+	widths := make([]int, len(headers))
+	links := 0
+
+	for col, header := range headers {
+		if err := setCell(f, name, col, 1, header); err != nil {
+			return err
+		}
+
+		widths[col] = len(header)
+	}
+
+	for i, e := range entries {
+		row := i + 2
+		values := append([]string{e.Query}, e.CsvRow()...)
+
+		for col, value := range values {
+			if value == "" {
+				continue
+			}
+
+			if err := writeValue(f, name, col, row, headers[col], value, st, &links); err != nil {
+				return err
+			}
+
+			for _, line := range strings.Split(value, "\n") {
+				if n := len([]rune(line)); n > widths[col] {
+					widths[col] = n
+				}
+			}
+		}
+	}
+
+	lastHeader, err := excelize.CoordinatesToCellName(len(headers), 1)
+	if err != nil {
+		return err
+	}
+
+	if err := f.SetCellStyle(name, "A1", lastHeader, st.header); err != nil {
+		return err
+	}
+
+	for col, width := range widths {
+		colName, err := excelize.ColumnNumberToName(col + 1)
+		if err != nil {
+			return err
+		}
+
+		width += 2
+		if width < minColWidth {
+			width = minColWidth
+		}
+
+		if width > maxColWidth {
+			width = maxColWidth
+		}
+
+		if err := f.SetColWidth(name, colName, colName, float64(width)); err != nil {
+			return err
+		}
+	}
+
+	if err := f.SetPanes(name, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return err
+	}
+
+	last, err := excelize.CoordinatesToCellName(len(headers), len(entries)+1)
+	if err != nil {
+		return err
+	}
+
+	return f.AutoFilter(name, "A1:"+last, nil)
+}
+
+// writeValue writes a CSV value as a typed cell of the column named header.
+// links counts the hyperlinks of the sheet so far.
+func writeValue(f *excelize.File, name string, col, row int, header, value string, st styles, links *int) error {
+	cell, err := excelize.CoordinatesToCellName(col+1, row)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case boolColumns[header]:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return f.SetCellBool(name, cell, b)
+		}
+	case numberColumns[header]:
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return f.SetCellFloat(name, cell, n, -1, 64)
+		}
+	case linkColumns[header] && isLink(value) && *links < maxSheetLinks:
+		if err := f.SetCellStr(name, cell, value); err != nil {
+			return err
+		}
+
+		if err := f.SetCellHyperLink(name, cell, value, "External"); err != nil {
+			return err
+		}
+
+		*links++
+
+		return f.SetCellStyle(name, cell, cell, st.link)
+	}
+
+	if err := f.SetCellStr(name, cell, value); err != nil {
+		return err
+	}
+
+	if strings.Contains(value, "\n") {
+		return f.SetCellStyle(name, cell, cell, st.wrap)
+	}
+
+	return nil
+}
+
+func setCell(f *excelize.File, name string, col, row int, value string) error {
+	cell, err := excelize.CoordinatesToCellName(col+1, row)
+	if err != nil {
+		return err
+	}
+
+	return f.SetCellStr(name, cell, value)
+}
+
+func isLink(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// sheetName makes a valid and unique sheet name of s: at most 31
+// characters without []:*?/\, unique regardless of case.
+func sheetName(s string, used map[string]bool) string {
+	s = strings.Join(strings.Fields(sheetNameReplacer.Replace(s)), " ")
+	s = strings.Trim(s, "'")
+
+	if s == "" {
+		s = defaultSheet
+	}
+
+	base := truncate(s, maxSheetName)
+	name := base
+
+	for i := 2; used[strings.ToLower(name)]; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		name = truncate(base, maxSheetName-len(suffix)) + suffix
+	}
+
+	used[strings.ToLower(name)] = true
+
+	return name
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return strings.TrimSpace(string(r[:n]))
+	}
+
+	return s
+}
@@ -0,0 +1,178 @@
+package xlsxwriter_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/wojciechkapala/google-maps-scraper/gmaps"
+	"github.com/wojciechkapala/google-maps-scraper/xlsxwriter"
+)
+
+func writeEntries(t *testing.T, sheetBy xlsxwriter.SheetBy, entries ...*gmaps.Entry) *excelize.File {
+	t.Helper()
+
+	in := make(chan scrapemate.Result, len(entries))
+	for _, e := range entries {
+		in <- scrapemate.Result{Data: e}
+	}
+
+	close(in)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, xlsxwriter.NewXLSXWriter(&buf, sheetBy).Run(context.Background(), in))
+
+	f, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = f.Close() })
+
+	return f
+}
+
+func column(t *testing.T, f *excelize.File, sheet, header string) string {
+	t.Helper()
+
+	rows, err := f.GetRows(sheet)
+	require.NoError(t, err)
+
+	for i, h := range rows[0] {
+		if h == header {
+			name, err := excelize.CoordinatesToCellName(i+1, 2)
+			require.NoError(t, err)
+
+			return name
+		}
+	}
+
+	t.Fatalf("no column %q", header)
+
+	return ""
+}
+
+func Test_XLSXWriter(t *testing.T) {
+	pompy := &gmaps.Entry{
+		Query:       "pompy ciepła Bytom",
+		Title:       "Pompy Łódź-Żółć",
+		City:        "Bytom",
+		WebSite:     "https://pompy.pl",
+		SocialLinks: map[string]string{"facebook": "https://facebook.com/pompy"},
+		CEIDG:       &gmaps.CompanyRegistration{Name: "Pompy", MatchScore: 0.75, LowConfidence: false},
+		Website:     &gmaps.WebsiteHealth{StatusCode: 200, HTTPS: true},
+	}
+	agd := &gmaps.Entry{Query: "pompy ciepła Bytom", Title: "AGD", City: "Bytom", SocialLinks: map[string]string{}}
+	gliwice := &gmaps.Entry{Query: "pompy ciepła Gliwice", Title: "Gliwickie Pompy", City: "Gliwice", SocialLinks: map[string]string{}}
+
+	f := writeEntries(t, xlsxwriter.SheetByQuery, pompy, agd, gliwice)
+	require.Equal(t, []string{"pompy ciepła Bytom", "pompy ciepła Gliwice"}, f.GetSheetList())
+
+	sheet := "pompy ciepła Bytom"
+
+	rows, err := f.GetRows(sheet)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	require.Equal(t, "query", rows[0][0])
+
+	// diacritics are kept
+	title, err := f.GetCellValue(sheet, column(t, f, sheet, "title"))
+	require.NoError(t, err)
+	require.Equal(t, "Pompy Łódź-Żółć", title)
+
+	// typed cells
+	cellType, err := f.GetCellType(sheet, column(t, f, sheet, "website_https"))
+	require.NoError(t, err)
+	require.Equal(t, excelize.CellTypeBool, cellType)
+
+	// a number is a cell without a type
+	cellType, err = f.GetCellType(sheet, column(t, f, sheet, "ceidg_match_score"))
+	require.NoError(t, err)
+	require.Equal(t, excelize.CellTypeUnset, cellType)
+
+	score, err := f.GetCellValue(sheet, column(t, f, sheet, "ceidg_match_score"), excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	require.Equal(t, "0.75", score)
+
+	// links
+	ok, link, err := f.GetCellHyperLink(sheet, column(t, f, sheet, "facebook"))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "https://facebook.com/pompy", link)
+
+	// frozen header
+	panes, err := f.GetPanes(sheet)
+	require.NoError(t, err)
+	require.True(t, panes.Freeze)
+	require.Equal(t, 1, panes.YSplit)
+}
+
+func Test_XLSXWriterSheetNames(t *testing.T) {
+	f := writeEntries(t, xlsxwriter.SheetByCity,
+		&gmaps.Entry{City: "Kraków", SocialLinks: map[string]string{}},
+		&gmaps.Entry{City: "", SocialLinks: map[string]string{}},
+		&gmaps.Entry{City: "Bardzo długa nazwa miasta: Wola / Kolonia", SocialLinks: map[string]string{}},
+	)
+
+	require.Equal(t, []string{"Kraków", "Results", "Bardzo długa nazwa miasta Wola"}, f.GetSheetList())
+
+	// an empty run still writes the header
+	f = writeEntries(t, xlsxwriter.SheetByQuery)
+	require.Equal(t, []string{"Results"}, f.GetSheetList())
+
+	rows, err := f.GetRows("Results")
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+}
+
+func Test_XLSXWriterLinkLimit(t *testing.T) {
+	// the real limit of 65530 makes excelize slow, every link is compared
+	// with all the links of the sheet
+	xlsxwriter.SetMaxSheetLinks(t, 3)
+
+	entries := make([]*gmaps.Entry, 4)
+	for i := range entries {
+		entries[i] = &gmaps.Entry{
+			Query:       "pompy ciepła Bytom",
+			WebSite:     fmt.Sprintf("https://firma%d.pl", i),
+			SocialLinks: map[string]string{},
+		}
+	}
+
+	f := writeEntries(t, xlsxwriter.SheetByQuery, entries...)
+	sheet := "pompy ciepła Bytom"
+
+	first := column(t, f, sheet, "website")
+	ok, link, err := f.GetCellHyperLink(sheet, first)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "https://firma0.pl", link)
+
+	col, _, err := excelize.CellNameToCoordinates(first)
+	require.NoError(t, err)
+
+	last, err := excelize.CoordinatesToCellName(col, len(entries)+1)
+	require.NoError(t, err)
+
+	// the link past the limit is a plain string
+	ok, _, err = f.GetCellHyperLink(sheet, last)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	value, err := f.GetCellValue(sheet, last)
+	require.NoError(t, err)
+	require.Equal(t, "https://firma3.pl", value)
+}
+
+func Test_ParseSheetBy(t *testing.T) {
+	sheetBy, err := xlsxwriter.ParseSheetBy("")
+	require.NoError(t, err)
+	require.Equal(t, xlsxwriter.SheetByQuery, sheetBy)
+
+	_, err = xlsxwriter.ParseSheetBy("region")
+	require.Error(t, err)
+}